@@ -0,0 +1,392 @@
+package routing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// routeCacheVersion 缓存格式版本，格式变化时旧缓存自动失效
+const routeCacheVersion = 2
+
+var (
+	RouteCacheStaleErr      = errors.New("route cache is stale")          // 路由定义已经变化，需要重新 Mount 并生成缓存
+	RouterNotMountedErr     = errors.New("router is not mounted")         // 生成缓存前需要先 Mount
+	RouterNotCacheableError = errors.New("router does not support cache") // 路由树不是 *Router，无法导出
+)
+
+// routeCache 编译好的路由表，路由树中的路由以其在 entries 中的下标表示
+type routeCache struct {
+	Version      int                               `json:"version"`
+	Checksum     string                            `json:"checksum"`
+	Routes       []cachedRoute                     `json:"routes"`
+	Routers      map[string]*cachedTree            `json:"routers"`
+	Hosts        map[string]map[string]*cachedTree `json:"hosts,omitempty"`
+	Prefixes     map[string]*cachedTree            `json:"prefixes,omitempty"`
+	HostPrefix   map[string]map[string]*cachedTree `json:"host_prefixes,omitempty"`
+	Fallbacks    map[string]*cachedTree            `json:"fallbacks,omitempty"`
+	HostFallback map[string]map[string]*cachedTree `json:"host_fallbacks,omitempty"`
+}
+
+type cachedRoute struct {
+	Methods  []string `json:"methods"`
+	Host     string   `json:"host,omitempty"`
+	Path     string   `json:"path"`
+	Name     string   `json:"name,omitempty"`
+	Prefix   bool     `json:"prefix,omitempty"`
+	Fallback bool     `json:"fallback,omitempty"`
+	Handler  string   `json:"handler"`
+}
+
+type cachedTree struct {
+	Paths      map[string]int           `json:"paths,omitempty"`
+	Params     map[string][]*cachedNode `json:"params,omitempty"`
+	Signatures []string                 `json:"signatures"`
+}
+
+type cachedNode struct {
+	Name     string                   `json:"name"`
+	Rule     string                   `json:"rule"`
+	Optional bool                     `json:"optional,omitempty"`
+	Terminal bool                     `json:"terminal,omitempty"`
+	Route    int                      `json:"route"`
+	Suffixes map[string]int           `json:"suffixes,omitempty"`
+	Nodes    map[string][]*cachedNode `json:"nodes,omitempty"`
+}
+
+func newCachedRoute(entry routeEntry) cachedRoute {
+	return cachedRoute{
+		Methods:  entry.route.Method(),
+		Host:     entry.route.GetHost(),
+		Path:     entry.route.GetPath(),
+		Name:     entry.route.GetName(),
+		Prefix:   entry.prefix,
+		Fallback: entry.fallback,
+		Handler:  FuncIdentity(entry.route.Handler()),
+	}
+}
+
+// routesChecksum 路由定义的校验和，路由的方法、域名、路径、名称、处理器、顺序或者影响路由树的选项变化时都会改变
+func routesChecksum(entries []routeEntry, options string) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "v%d|%s\n", routeCacheVersion, options)
+	for _, entry := range entries {
+		route := newCachedRoute(entry)
+		_, _ = fmt.Fprintf(hash, "%t|%t|%s|%s|%s|%s|%s\n",
+			route.Prefix, route.Fallback, strings.Join(route.Methods, ","), route.Host, route.Path, route.Name, route.Handler)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// checksum 缓存的校验和，同时覆盖路由定义和缓存中的路由树，缓存文件被修改后校验和不再一致
+func (cache routeCache) checksum(routes string) (string, error) {
+	cache.Checksum = ""
+	content, err := json.Marshal(cache)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s\n", routes)
+	_, _ = hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Cache 把 Mount 之后编译好的路由表写入 w，生产环境启动时可以通过 LoadCache 跳过路由解析
+func (httpRouter *HttpRouter) Cache(w io.Writer) error {
+	if httpRouter.names == nil {
+		return RouterNotMountedErr
+	}
+
+	entries := httpRouter.mountedEntries
+	indexes := make(map[contracts.Route]int, len(entries))
+	cache := routeCache{
+		Version: routeCacheVersion,
+		Routes:  make([]cachedRoute, 0, len(entries)),
+	}
+	for i, entry := range entries {
+		indexes[entry.route] = i
+		cache.Routes = append(cache.Routes, newCachedRoute(entry))
+	}
+	index := func(route contracts.Route) int {
+		if i, exists := indexes[route]; exists {
+			return i
+		}
+		return -1
+	}
+
+	var err error
+	if cache.Routers, err = exportRouters(httpRouter.routers, index); err != nil {
+		return err
+	}
+	for host, routers := range httpRouter.hostRouters {
+		if cache.Hosts == nil {
+			cache.Hosts = map[string]map[string]*cachedTree{}
+		}
+		if cache.Hosts[host], err = exportRouters(routers, index); err != nil {
+			return err
+		}
+	}
+	if cache.Prefixes, cache.HostPrefix, err = exportPrefixTable(httpRouter.prefixes, index); err != nil {
+		return err
+	}
+	if cache.Fallbacks, cache.HostFallback, err = exportPrefixTable(httpRouter.fallbacks, index); err != nil {
+		return err
+	}
+	if cache.Checksum, err = cache.checksum(routesChecksum(entries, httpRouter.optionsSignature())); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(cache)
+}
+
+// LoadCache 代替 Mount，从 Cache 生成的缓存中恢复路由树。
+// 路由仍然需要照常注册，缓存的校验和与当前路由定义不一致，或者缓存中的路由树无效时返回 RouteCacheStaleErr，此时应该调用 Mount 并重新生成缓存
+func (httpRouter *HttpRouter) LoadCache(r io.Reader) error {
+	var cache routeCache
+	if err := json.NewDecoder(r).Decode(&cache); err != nil {
+		return fmt.Errorf("invalid route cache: %w", err)
+	}
+
+	entries := httpRouter.entries()
+	if cache.Version != routeCacheVersion || len(cache.Routes) != len(entries) {
+		return RouteCacheStaleErr
+	}
+	if checksum, err := cache.checksum(routesChecksum(entries, httpRouter.optionsSignature())); err != nil || checksum != cache.Checksum {
+		return RouteCacheStaleErr
+	}
+	compiled := map[string]*regexp.Regexp{}
+	if err := cache.validate(compiled); err != nil {
+		return fmt.Errorf("%w: %v", RouteCacheStaleErr, err)
+	}
+
+	entries, unknownMiddlewares := httpRouter.prepare()
+	route := func(i int) contracts.Route {
+		if i < 0 {
+			return nil
+		}
+		return entries[i].route
+	}
+	restore := func(tree *cachedTree) *Router[contracts.Route] {
+		router := importRouter(tree, route, compiled)
+		httpRouter.configure(router)
+		return router
+	}
+
+	for method, tree := range cache.Routers {
+		httpRouter.routers[method] = restore(tree)
+	}
+	httpRouter.hostRouters = make(map[string]map[string]contracts.Router[contracts.Route])
+	for host, trees := range cache.Hosts {
+		httpRouter.hostRouters[host] = map[string]contracts.Router[contracts.Route]{}
+		for method, tree := range trees {
+			httpRouter.hostRouters[host][method] = restore(tree)
+		}
+	}
+	importPrefixTable(httpRouter.prefixes, cache.Prefixes, cache.HostPrefix, restore)
+	importPrefixTable(httpRouter.fallbacks, cache.Fallbacks, cache.HostFallback, restore)
+
+	return httpRouter.finish(entries, unknownMiddlewares, httpRouter.buildHosts())
+}
+
+// validate 在恢复路由树之前检查缓存中的每棵树，约束编译后存入 compiled，恢复时不会再失败
+func (cache *routeCache) validate(compiled map[string]*regexp.Regexp) error {
+	var trees []*cachedTree
+	for _, routers := range []map[string]*cachedTree{cache.Routers, cache.Prefixes, cache.Fallbacks} {
+		for _, tree := range routers {
+			trees = append(trees, tree)
+		}
+	}
+	for _, hosts := range []map[string]map[string]*cachedTree{cache.Hosts, cache.HostPrefix, cache.HostFallback} {
+		for _, routers := range hosts {
+			for _, tree := range routers {
+				trees = append(trees, tree)
+			}
+		}
+	}
+
+	for _, tree := range trees {
+		if tree == nil {
+			return errors.New("empty route tree")
+		}
+		for path, i := range tree.Paths {
+			if err := cache.validateIndex(i); err != nil {
+				return fmt.Errorf("path %s: %w", path, err)
+			}
+		}
+		if err := cache.validateNodes(tree.Params, compiled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cache *routeCache) validateNodes(tree map[string][]*cachedNode, compiled map[string]*regexp.Regexp) error {
+	for _, nodes := range tree {
+		for _, node := range nodes {
+			if node == nil {
+				return errors.New("empty route node")
+			}
+			if _, exists := compiled[node.Rule]; !exists {
+				reg, err := regexp.Compile(node.Rule)
+				if err != nil {
+					return fmt.Errorf("param %s: %w", node.Name, err)
+				}
+				compiled[node.Rule] = reg
+			}
+			if err := cache.validateIndex(node.Route); err != nil {
+				return fmt.Errorf("param %s: %w", node.Name, err)
+			}
+			for _, i := range node.Suffixes {
+				if err := cache.validateIndex(i); err != nil {
+					return fmt.Errorf("param %s: %w", node.Name, err)
+				}
+			}
+			if err := cache.validateNodes(node.Nodes, compiled); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateIndex 路由下标必须指向缓存中的路由，-1 表示没有路由
+func (cache *routeCache) validateIndex(i int) error {
+	if i < -1 || i >= len(cache.Routes) {
+		return fmt.Errorf("route index %d out of range", i)
+	}
+	return nil
+}
+
+func exportRouters[R contracts.Router[contracts.Route]](routers map[string]R, index func(contracts.Route) int) (map[string]*cachedTree, error) {
+	trees := make(map[string]*cachedTree, len(routers))
+	for method, router := range routers {
+		instance, isRouter := any(router).(*Router[contracts.Route])
+		if !isRouter {
+			return nil, fmt.Errorf("%w: %T", RouterNotCacheableError, router)
+		}
+		trees[method] = instance.export(index)
+	}
+	return trees, nil
+}
+
+// exportPrefixTable 导出前缀路由表中不限域名以及按域名分组的前缀路由树
+func exportPrefixTable(table *prefixTable, index func(contracts.Route) int) (map[string]*cachedTree, map[string]map[string]*cachedTree, error) {
+	trees, err := exportRouters(table.routers, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	var hostTrees map[string]map[string]*cachedTree
+	for host, routers := range table.hostRouters {
+		if hostTrees == nil {
+			hostTrees = map[string]map[string]*cachedTree{}
+		}
+		if hostTrees[host], err = exportRouters(routers, index); err != nil {
+			return nil, nil, err
+		}
+	}
+	return trees, hostTrees, nil
+}
+
+// importPrefixTable 从缓存恢复前缀路由表，域名路由树在 buildHosts 中重新建立
+func importPrefixTable(table *prefixTable, trees map[string]*cachedTree, hostTrees map[string]map[string]*cachedTree, restore func(*cachedTree) *Router[contracts.Route]) {
+	for method, tree := range trees {
+		table.routers[method] = restore(tree)
+	}
+	table.hostRouters = make(map[string]map[string]PrefixRouter[contracts.Route])
+	for host, routers := range hostTrees {
+		table.hostRouters[host] = map[string]PrefixRouter[contracts.Route]{}
+		for method, tree := range routers {
+			table.hostRouters[host][method] = restore(tree)
+		}
+	}
+}
+
+// export 导出路由树，index 把路由数据转换为下标
+func (router *Router[T]) export(index func(T) int) *cachedTree {
+	tree := &cachedTree{
+		Paths:  make(map[string]int, len(router.paths)),
+		Params: exportNodes(router.paramsRoutes, index),
+	}
+	for path, data := range router.paths {
+		tree.Paths[path] = index(data)
+	}
+	for signature := range router.signatures {
+		tree.Signatures = append(tree.Signatures, signature)
+	}
+	sort.Strings(tree.Signatures)
+	return tree
+}
+
+func exportNodes[T any](tree map[string][]*RouterNode[T], index func(T) int) map[string][]*cachedNode {
+	results := make(map[string][]*cachedNode, len(tree))
+	for prefix, nodes := range tree {
+		results[prefix] = make([]*cachedNode, 0, len(nodes))
+		for _, node := range nodes {
+			cached := &cachedNode{
+				Name:     node.name,
+				Rule:     node.rule,
+				Optional: node.optional,
+				Terminal: node.terminal,
+				Route:    -1,
+				Nodes:    exportNodes(node.nodes, index),
+			}
+			if node.terminal {
+				cached.Route = index(node.data)
+			}
+			for suffix, data := range node.suffixes {
+				if cached.Suffixes == nil {
+					cached.Suffixes = map[string]int{}
+				}
+				cached.Suffixes[suffix] = index(data)
+			}
+			results[prefix] = append(results[prefix], cached)
+		}
+	}
+	return results
+}
+
+// importRouter 从缓存恢复路由树，compiled 中是 validate 编译好的约束
+func importRouter[T any](tree *cachedTree, data func(int) T, compiled map[string]*regexp.Regexp) *Router[T] {
+	router := newRouter[T]()
+	for path, i := range tree.Paths {
+		router.paths[path] = data(i)
+	}
+	router.paramsRoutes = importNodes(tree.Params, data, compiled)
+	router.prefixes = sortedPrefixes(router.paramsRoutes)
+	for _, signature := range tree.Signatures {
+		router.signatures[signature] = struct{}{}
+	}
+	return router
+}
+
+func importNodes[T any](tree map[string][]*cachedNode, data func(int) T, compiled map[string]*regexp.Regexp) map[string][]*RouterNode[T] {
+	results := make(map[string][]*RouterNode[T], len(tree))
+	for prefix, nodes := range tree {
+		results[prefix] = make([]*RouterNode[T], 0, len(nodes))
+		for _, cached := range nodes {
+			node := &RouterNode[T]{
+				data:     data(cached.Route),
+				optional: cached.Optional,
+				name:     cached.Name,
+				rule:     cached.Rule,
+				reg:      compiled[cached.Rule],
+				nodes:    importNodes(cached.Nodes, data, compiled),
+				terminal: cached.Terminal,
+				suffixes: make(map[string]T, len(cached.Suffixes)),
+			}
+			node.prefixes = sortedPrefixes(node.nodes)
+			for suffix, i := range cached.Suffixes {
+				node.suffixes[suffix] = data(i)
+			}
+			results[prefix] = append(results[prefix], node)
+		}
+	}
+	return results
+}
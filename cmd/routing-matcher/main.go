@@ -0,0 +1,64 @@
+// routing-matcher 根据路由缓存或者路由配置文件生成编译好的路由匹配器，通常配合 go generate 使用：
+//
+//	//go:generate go run github.com/goal-web/routing/cmd/routing-matcher -cache routes.cache -method GET -name ApiMatcher -o api_matcher.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/goal-web/routing"
+	"os"
+)
+
+func main() {
+	var (
+		cacheFile   = flag.String("cache", "", "HttpRouter.Cache 生成的路由缓存文件")
+		configFile  = flag.String("config", "", "路由配置文件，支持 .json、.yaml、.yml 和 .toml")
+		method      = flag.String("method", "", "只编译指定方法的路由，为空时编译所有方法的路由")
+		packageName = flag.String("package", os.Getenv("GOPACKAGE"), "生成文件的包名，默认为 go generate 所在的包")
+		name        = flag.String("name", "Matcher", "生成的类型名")
+		output      = flag.String("o", "", "输出文件，为空时输出到标准输出")
+	)
+	flag.Parse()
+
+	if err := run(*cacheFile, *configFile, *method, *packageName, *name, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "routing-matcher:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cacheFile, configFile, method, packageName, name, output string) error {
+	var routes []string
+	switch {
+	case cacheFile != "" && configFile != "":
+		return fmt.Errorf("only one of -cache and -config can be used")
+	case cacheFile != "":
+		file, err := os.Open(cacheFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if routes, err = routing.MatcherRoutesFromCache(file, method); err != nil {
+			return err
+		}
+	case configFile != "":
+		configs, err := routing.LoadRouteConfig(configFile)
+		if err != nil {
+			return err
+		}
+		routes = routing.MatcherRoutesFromConfig(configs, method)
+	default:
+		return fmt.Errorf("-cache or -config is required")
+	}
+
+	var source bytes.Buffer
+	if err := routing.GenerateMatcher(&source, routing.MatcherOptions{Package: packageName, Name: name, Routes: routes}); err != nil {
+		return err
+	}
+	if output == "" {
+		_, err := os.Stdout.Write(source.Bytes())
+		return err
+	}
+	return os.WriteFile(output, source.Bytes(), 0o644)
+}
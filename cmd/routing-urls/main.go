@@ -0,0 +1,62 @@
+// routing-urls 根据路由缓存或者路由配置文件中的命名路由生成类型安全的 URL 函数，通常配合 go generate 使用：
+//
+//	//go:generate go run github.com/goal-web/routing/cmd/routing-urls -cache routes.cache -package routes -o urls.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/goal-web/routing"
+	"os"
+)
+
+func main() {
+	var (
+		cacheFile   = flag.String("cache", "", "HttpRouter.Cache 生成的路由缓存文件")
+		configFile  = flag.String("config", "", "路由配置文件，支持 .json、.yaml、.yml 和 .toml")
+		packageName = flag.String("package", os.Getenv("GOPACKAGE"), "生成文件的包名，默认为 go generate 所在的包")
+		output      = flag.String("o", "", "输出文件，为空时输出到标准输出")
+	)
+	flag.Parse()
+
+	if err := run(*cacheFile, *configFile, *packageName, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "routing-urls:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cacheFile, configFile, packageName, output string) error {
+	var routes map[string]string
+	switch {
+	case cacheFile != "" && configFile != "":
+		return fmt.Errorf("only one of -cache and -config can be used")
+	case cacheFile != "":
+		file, err := os.Open(cacheFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if routes, err = routing.NamedRoutesFromCache(file); err != nil {
+			return err
+		}
+	case configFile != "":
+		configs, err := routing.LoadRouteConfig(configFile)
+		if err != nil {
+			return err
+		}
+		routes = routing.NamedRoutesFromConfig(configs)
+	default:
+		return fmt.Errorf("-cache or -config is required")
+	}
+
+	var source bytes.Buffer
+	if err := routing.GenerateURLHelpers(&source, packageName, routes); err != nil {
+		return err
+	}
+	if output == "" {
+		_, err := os.Stdout.Write(source.Bytes())
+		return err
+	}
+	return os.WriteFile(output, source.Bytes(), 0o644)
+}
@@ -0,0 +1,481 @@
+// Code generated by routing-matcher. DO NOT EDIT.
+
+package routing_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/routing"
+)
+
+// ConformanceMatcher 编译好的路由匹配器，只能添加生成时已知的路由
+type ConformanceMatcher[T any] struct {
+	data  [13]T
+	added [13]bool
+}
+
+var _ contracts.Router[any] = (*ConformanceMatcher[any])(nil)
+
+func NewConformanceMatcher[T any]() contracts.Router[T] {
+	return &ConformanceMatcher[T]{}
+}
+
+var conformanceMatcherPatterns = map[string]int{
+	"/archives/{id:[0-9]+?}":                        0,
+	"/articles/first_{type}":                        1,
+	"/articles1/first_{type?}":                      2,
+	"/books/{name}_description":                     3,
+	"/books1/{name?}_description":                   4,
+	"/category/{category:[0-9]+}/archive/{archive}": 5,
+	"/category/{category}/posts/{archive:[0-9]+}":   6,
+	"/homepage/{name?}/hosts":                       7,
+	"/homepage/{name?}/news":                        8,
+	"/homepage/{xx}/hosts":                          7,
+	"/posts/{id}":                                   9,
+	"/posts/{name}":                                 9,
+	"/users":                                        10,
+	"/users/{name}":                                 11,
+	"/users1/{name}/{level?}":                       12,
+	"/users1/{xx}/{xxx:.*}":                         12,
+}
+
+var conformanceMatcherSignatures = [13]string{
+	"/archives/[0-9]+",
+	"/articles/first_.*",
+	"/articles1/first_.*",
+	"/books/.*_description",
+	"/books1/.*_description",
+	"/category/[0-9]+/archive/.*",
+	"/category/.*/posts/[0-9]+",
+	"/homepage/.*/hosts",
+	"/homepage/.*/news",
+	"/posts/.*",
+	"/users",
+	"/users/.*",
+	"/users1/.*/.*",
+}
+
+func (m *ConformanceMatcher[T]) Add(route string, data T) (string, error) {
+	slot, exists := conformanceMatcherPatterns[route]
+	if !exists {
+		return route, fmt.Errorf("%w: %s", routing.RouteNotCompiledErr, route)
+	}
+	if m.added[slot] {
+		return conformanceMatcherSignatures[slot], routing.RouteHasExists
+	}
+	m.data[slot], m.added[slot] = data, true
+	return conformanceMatcherSignatures[slot], nil
+}
+
+func (m *ConformanceMatcher[T]) IsEmpty() bool {
+	for _, added := range m.added {
+		if added {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *ConformanceMatcher[T]) Find(path string) (T, contracts.RouteParams, error) {
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+	switch path {
+	case "/users":
+		if m.added[10] {
+			return m.data[10], nil, nil
+		}
+	}
+	params := make(contracts.RouteParams)
+	if result, found := m.walk0(path, params); found {
+		return result, params, nil
+	}
+	var zero T
+	return zero, params, routing.NotFoundErr
+}
+
+// accept 命中路由，路由未添加时继续查找
+func (m *ConformanceMatcher[T]) accept(slot int, name, value string, params contracts.RouteParams) (T, bool) {
+	if !m.added[slot] {
+		var zero T
+		return zero, false
+	}
+	params[name] = value
+	return m.data[slot], true
+}
+
+// descend 参数值已通过约束，继续匹配剩余路径
+func (m *ConformanceMatcher[T]) descend(name, value, rest, sub string, slot int, children bool, walk func(string, contracts.RouteParams) (T, bool), params contracts.RouteParams) (T, bool) {
+	if slot >= 0 && rest == sub {
+		if result, found := m.accept(slot, name, value, params); found {
+			return result, true
+		}
+		if !children {
+			var zero T
+			return zero, false
+		}
+	}
+	params[name] = value
+	if result, found := walk(rest, params); found {
+		return result, true
+	}
+	delete(params, name)
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk0(path string, params contracts.RouteParams) (T, bool) {
+	if value, ok := conformanceMatcherCut(path, "/articles1/first_"); ok {
+		if result, found := m.node1(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/articles/first_"); ok {
+		if result, found := m.node3(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/archives/"); ok {
+		if result, found := m.node5(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/category/"); ok {
+		if result, found := m.node7(value, params); found {
+			return result, true
+		}
+		if result, found := m.node11(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/homepage/"); ok {
+		if result, found := m.node15(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/books1/"); ok {
+		if result, found := m.node17(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/users1/"); ok {
+		if result, found := m.node19(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/books/"); ok {
+		if result, found := m.node23(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/posts/"); ok {
+		if result, found := m.node25(value, params); found {
+			return result, true
+		}
+	}
+	if value, ok := conformanceMatcherCut(path, "/users/"); ok {
+		if result, found := m.node27(value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// node1 {type:.*}
+func (m *ConformanceMatcher[T]) node1(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(2, "type", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk2(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node3 {type:.*}
+func (m *ConformanceMatcher[T]) node3(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(1, "type", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk4(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node5 {id:[0-9]+}
+func (m *ConformanceMatcher[T]) node5(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") && (conformanceMatcherRule0(value) || value == "") {
+		if result, found := m.accept(0, "id", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk6(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node7 {category:[0-9]+}
+func (m *ConformanceMatcher[T]) node7(value string, params contracts.RouteParams) (T, bool) {
+	if index := strings.Index(value, "/archive/"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") && conformanceMatcherRule0(head) {
+			if result, found := m.descend("category", head, value[index:], "/archive/", -1, true, m.walk8, params); found {
+				return result, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk8(path string, params contracts.RouteParams) (T, bool) {
+	if value, ok := conformanceMatcherCut(path, "/archive/"); ok {
+		if result, found := m.node9(value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// node9 {archive:.*}
+func (m *ConformanceMatcher[T]) node9(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(5, "archive", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk10(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node11 {category:.*}
+func (m *ConformanceMatcher[T]) node11(value string, params contracts.RouteParams) (T, bool) {
+	if index := strings.Index(value, "/posts/"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") {
+			if result, found := m.descend("category", head, value[index:], "/posts/", -1, true, m.walk12, params); found {
+				return result, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk12(path string, params contracts.RouteParams) (T, bool) {
+	if value, ok := conformanceMatcherCut(path, "/posts/"); ok {
+		if result, found := m.node13(value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// node13 {archive:[0-9]+}
+func (m *ConformanceMatcher[T]) node13(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") && conformanceMatcherRule0(value) {
+		if result, found := m.accept(6, "archive", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk14(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node15 {name:.*}
+func (m *ConformanceMatcher[T]) node15(value string, params contracts.RouteParams) (T, bool) {
+	if index := strings.Index(value, "/hosts"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") {
+			if result, found := m.descend("name", head, value[index:], "/hosts", 7, false, m.walk16, params); found {
+				return result, true
+			}
+		}
+	} else if "/"+value == "/hosts" {
+		if result, found := m.accept(7, "name", "", params); found {
+			return result, true
+		}
+	}
+	if index := strings.Index(value, "/news"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") {
+			if result, found := m.descend("name", head, value[index:], "/news", 8, false, m.walk16, params); found {
+				return result, true
+			}
+		}
+	} else if "/"+value == "/news" {
+		if result, found := m.accept(8, "name", "", params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk16(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node17 {name:.*}
+func (m *ConformanceMatcher[T]) node17(value string, params contracts.RouteParams) (T, bool) {
+	if index := strings.Index(value, "_description"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") {
+			if result, found := m.descend("name", head, value[index:], "_description", 4, false, m.walk18, params); found {
+				return result, true
+			}
+		}
+	} else if "/"+value == "_description" {
+		if result, found := m.accept(4, "name", "", params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk18(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node19 {name:.*}
+func (m *ConformanceMatcher[T]) node19(value string, params contracts.RouteParams) (T, bool) {
+	{
+		head, rest := conformanceMatcherSegment(value)
+		if result, found := m.descend("name", head, rest, "/", -1, true, m.walk20, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk20(path string, params contracts.RouteParams) (T, bool) {
+	if value, ok := conformanceMatcherCut(path, "/"); ok {
+		if result, found := m.node21(value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// node21 {level:.*}
+func (m *ConformanceMatcher[T]) node21(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(12, "level", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk22(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node23 {name:.*}
+func (m *ConformanceMatcher[T]) node23(value string, params contracts.RouteParams) (T, bool) {
+	if index := strings.Index(value, "_description"); index > -1 {
+		if head := value[:index]; !strings.Contains(head, "/") {
+			if result, found := m.descend("name", head, value[index:], "_description", 3, false, m.walk24, params); found {
+				return result, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk24(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node25 {id:.*}
+func (m *ConformanceMatcher[T]) node25(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(9, "id", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk26(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// node27 {name:.*}
+func (m *ConformanceMatcher[T]) node27(value string, params contracts.RouteParams) (T, bool) {
+	if !strings.Contains(value, "/") {
+		if result, found := m.accept(11, "name", value, params); found {
+			return result, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (m *ConformanceMatcher[T]) walk28(path string, params contracts.RouteParams) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+func conformanceMatcherCut(path, prefix string) (string, bool) {
+	if strings.HasPrefix(path, prefix) {
+		return path[len(prefix):], true
+	}
+	if strings.HasSuffix(prefix, "/") && strings.HasPrefix(path+"/", prefix) {
+		return "", true
+	}
+	return "", false
+}
+
+func conformanceMatcherSegment(value string) (string, string) {
+	if index := strings.IndexByte(value, '/'); index > -1 {
+		return value[:index], value[index:]
+	}
+	return value, "/"
+}
+
+// conformanceMatcherRule0 [0-9]+
+func conformanceMatcherRule0(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c >= '0' && c <= '9' {
+			return true
+		}
+	}
+	return false
+}
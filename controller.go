@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"fmt"
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"reflect"
+	"strings"
+)
+
+// ControllerRoute 控制器路由描述
+type ControllerRoute struct {
+	Method      any    // 请求方法，字符串或者字符串数组
+	Path        string // 相对于控制器前缀的路径
+	Action      string // 控制器的导出方法名
+	Name        string // 路由名，为空时使用 前缀命名空间.方法名 的小驼峰形式
+	Middlewares []any
+}
+
+// RoutesDescriber 控制器实现该接口时按 Routes 的描述注册路由，否则按约定注册 Index、Create、Store、Show、Edit、Update、Destroy
+type RoutesDescriber interface {
+	Routes() []ControllerRoute
+}
+
+// controllerAction 控制器方法的处理器，第一个参数是控制器本身，每次请求由容器解析，
+// 容器无法解析时复制一份注册时的控制器
+type controllerAction struct {
+	contracts.MagicalFunc
+	controller reflect.Value
+	identity   string
+}
+
+func (action *controllerAction) Call(args []reflect.Value) []reflect.Value {
+	if len(args) > 0 && (!args[0].IsValid() || args[0].IsZero()) {
+		args = append([]reflect.Value{action.newController()}, args[1:]...)
+	}
+	return action.MagicalFunc.Call(args)
+}
+
+func (action *controllerAction) newController() reflect.Value {
+	if action.controller.Kind() != reflect.Pointer {
+		return action.controller
+	}
+	instance := reflect.New(action.controller.Type().Elem())
+	instance.Elem().Set(action.controller.Elem())
+	return instance
+}
+
+// controllerRoutes 返回控制器需要注册的路由
+func controllerRoutes(controller any) []ControllerRoute {
+	if describer, ok := controller.(RoutesDescriber); ok {
+		return describer.Routes()
+	}
+
+	var routes []ControllerRoute
+	var controllerType = reflect.TypeOf(controller)
+	for _, action := range resourceActions {
+		name := strings.ToUpper(action.name[:1]) + action.name[1:]
+		if _, exists := controllerType.MethodByName(name); !exists {
+			continue
+		}
+		routes = append(routes, ControllerRoute{
+			Method: action.methods,
+			Path:   strings.Replace(strings.Replace(action.path, "{prefix}", "", 1), "{param}", "{id}", 1),
+			Action: name,
+		})
+	}
+	return routes
+}
+
+// newControllerAction 把控制器方法转换为处理器
+func newControllerAction(controller any, action string) contracts.MagicalFunc {
+	method, exists := reflect.TypeOf(controller).MethodByName(action)
+	if !exists {
+		panic(fmt.Errorf("controller %T has no method %s", controller, action))
+	}
+	return &controllerAction{
+		MagicalFunc: container.NewMagicalFunc(method.Func.Interface()),
+		controller:  reflect.ValueOf(controller),
+		identity:    fmt.Sprintf("%T.%s", controller, action),
+	}
+}
+
+// Controller 在 prefix 下注册控制器的路由，每个方法生成一个普通路由，路由名默认为 前缀命名空间.方法名，例如 /users 下的 Show 为 users.show
+func (group *Group) Controller(prefix string, controller any, middlewares ...any) contracts.RouteGroup {
+	controllerGroup := group.Group(prefix, middlewares...).(*Group)
+	namespace := namespaceOf(controllerGroup.prefix)
+
+	for _, route := range controllerRoutes(controller) {
+		controllerGroup.Add(route.Method, strings.TrimSuffix(route.Path, "/"), newControllerAction(controller, route.Action), route.Middlewares...)
+
+		name := route.Name
+		if name == "" {
+			name = namespaced(namespace, strings.ToLower(route.Action[:1])+route.Action[1:])
+		}
+		controllerGroup.routes[len(controllerGroup.routes)-1].Name(name)
+	}
+
+	return controllerGroup
+}
+
+// Controller 在 prefix 下注册控制器的路由，参考 Group.Controller
+func (httpRouter *HttpRouter) Controller(prefix string, controller any, middlewares ...any) contracts.RouteGroup {
+	group := httpRouter.Group("").(*Group)
+	return group.Controller(prefix, controller, middlewares...)
+}
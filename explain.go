@@ -0,0 +1,87 @@
+package routing
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+// Match FindAll 返回的一个匹配结果
+type Match[T any] struct {
+	Data   T
+	Params contracts.RouteParams
+}
+
+// TraceStep Explain 返回的单个匹配步骤
+type TraceStep struct {
+	Depth    int    // 参数树的嵌套深度
+	Prefix   string // 当前访问的静态前缀
+	Param    string // 参数名，为空表示只是在比较前缀
+	Rule     string // 参数约束
+	Optional bool   // 是否可选参数
+	Value    string // 被测试的值
+	Accepted bool
+	Reason   string // 通过或者被拒绝的原因
+}
+
+// FindAll 按优先级返回所有匹配的结果，静态路由优先于参数路由，与 Find 一致，静态路由的参数为 nil
+func (router *Router[T]) FindAll(path string) ([]Match[T], error) {
+	path = router.trimPath(path)
+
+	var results []Match[T]
+	if result, ok := router.static(path); ok {
+		results = append(results, Match[T]{Data: result})
+	}
+
+	var m = &matcher[T]{
+		fold:   router.caseInsensitive,
+		params: make(contracts.RouteParams),
+		hit: func(data T, params contracts.RouteParams) bool {
+			results = append(results, Match[T]{Data: data, Params: copyParams(params)})
+			return false
+		},
+	}
+	m.walk(path, router.paramsRoutes, router.prefixes, 0)
+
+	if len(results) == 0 {
+		return nil, NotFoundErr
+	}
+	return results, nil
+}
+
+// Explain 记录匹配 path 时访问过的每个前缀和节点，以及每个参数值通过或被拒绝的原因
+func (router *Router[T]) Explain(path string) []TraceStep {
+	path = router.trimPath(path)
+
+	var steps []TraceStep
+	if _, ok := router.static(path); ok {
+		steps = append(steps, TraceStep{Prefix: path, Value: path, Accepted: true, Reason: "static route matched"})
+	} else {
+		steps = append(steps, TraceStep{Prefix: path, Value: path, Reason: "no static route"})
+	}
+
+	var m = &matcher[T]{
+		fold:   router.caseInsensitive,
+		params: make(contracts.RouteParams),
+		steps:  &steps,
+		hit: func(T, contracts.RouteParams) bool {
+			return false
+		},
+	}
+	m.walk(path, router.paramsRoutes, router.prefixes, 0)
+
+	return steps
+}
+
+func (router *Router[T]) trimPath(path string) string {
+	if router.strictSlash {
+		return path
+	}
+	return trimPath(path)
+}
+
+func copyParams(params contracts.RouteParams) contracts.RouteParams {
+	results := make(contracts.RouteParams, len(params))
+	for key, value := range params {
+		results[key] = value
+	}
+	return results
+}
@@ -1,3 +1,8 @@
 module github.com/goal-web/routing
 
 go 1.20
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
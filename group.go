@@ -2,9 +2,9 @@ package routing
 
 import (
 	"errors"
-	"github.com/goal-web/container"
 	"github.com/goal-web/contracts"
 	"github.com/labstack/echo/v4"
+	"strings"
 )
 
 var (
@@ -17,33 +17,66 @@ type Group struct {
 	middlewares []contracts.MagicalFunc
 	routes      []contracts.Route
 	groups      []contracts.RouteGroup
+
+	parent              *Group
+	excludedMiddlewares []any
+	prefixRoutes        []contracts.Route
+	fallbacks           []contracts.Route
+	meta                map[string]any
+	app                 contracts.Application // 通过 HttpRouter.Group 创建时的容器，子组继承上级组的容器
+	router              *HttpRouter           // 通过 HttpRouter.Group 创建时所属的路由器，子组继承上级组的路由器
 }
 
+// GetHost 返回组的域名，没有设置时继承上级组的域名
 func (group *Group) GetHost() string {
+	if group.host == "" && group.parent != nil {
+		return group.parent.GetHost()
+	}
 	return group.host
 }
 
+// application 返回组所属路由的容器，没有设置时继承上级组的容器
+func (group *Group) application() contracts.Application {
+	if group.app == nil && group.parent != nil {
+		return group.parent.application()
+	}
+	return group.app
+}
+
+// httpRouter 返回组所属的路由器，没有设置时继承上级组的路由器
+func (group *Group) httpRouter() *HttpRouter {
+	if group.router == nil && group.parent != nil {
+		return group.parent.httpRouter()
+	}
+	return group.router
+}
+
 func (group *Group) Host(host string) contracts.RouteGroup {
 	group.host = host
 	return group
 }
 
 func NewGroup(prefix string, middlewares ...any) contracts.RouteGroup {
+	middlewares, excluded := splitExclusions(middlewares)
 	return &Group{
-		prefix:      prefix,
-		routes:      make([]contracts.Route, 0),
-		groups:      make([]contracts.RouteGroup, 0),
-		middlewares: ConvertToMiddlewares(middlewares...),
+		prefix:              prefix,
+		routes:              make([]contracts.Route, 0),
+		groups:              make([]contracts.RouteGroup, 0),
+		middlewares:         ConvertToMiddlewares(middlewares...),
+		excludedMiddlewares: excluded,
 	}
 }
 
 // Group 添加一个子组
 func (group *Group) Group(prefix string, middlewares ...any) contracts.RouteGroup {
+	middlewares, excluded := splitExclusions(middlewares)
 	var groupInstance = &Group{
-		prefix:      group.prefix + prefix,
-		routes:      make([]contracts.Route, 0),
-		groups:      make([]contracts.RouteGroup, 0),
-		middlewares: append(group.middlewares, ConvertToMiddlewares(middlewares...)...),
+		prefix:              group.prefix + prefix,
+		routes:              make([]contracts.Route, 0),
+		groups:              make([]contracts.RouteGroup, 0),
+		middlewares:         mergeMiddlewares(group.middlewares, ConvertToMiddlewares(middlewares...)),
+		parent:              group,
+		excludedMiddlewares: excluded,
 	}
 
 	group.groups = append(group.groups, groupInstance)
@@ -62,16 +95,26 @@ func (group *Group) Add(method any, path string, handler any, middlewares ...any
 	default:
 		panic(MethodTypeError)
 	}
+	middlewares, excluded := splitExclusions(middlewares)
 	group.routes = append(group.routes, &Route{
-		method:      methods,
-		path:        group.prefix + path,
-		middlewares: append(group.middlewares, ConvertToMiddlewares(middlewares...)...),
-		handler:     container.NewMagicalFunc(handler),
+		method:              methods,
+		path:                group.prefix + path,
+		middlewares:         mergeMiddlewares(group.middlewares, ConvertToMiddlewares(middlewares...)),
+		handler:             toHandler(handler),
+		group:               group,
+		inherited:           len(group.middlewares),
+		excludedMiddlewares: excluded,
 	})
 
 	return group
 }
 
+// WithoutMiddleware 从组内所有路由和子组继承的中间件中排除指定的中间件，可以是别名、中间件实例或者函数
+func (group *Group) WithoutMiddleware(middlewares ...any) contracts.RouteGroup {
+	group.excludedMiddlewares = append(group.excludedMiddlewares, checkExcluded(middlewares)...)
+	return group
+}
+
 func (group *Group) Get(path string, handler any, middlewares ...any) contracts.RouteGroup {
 	return group.Add(echo.GET, path, handler, middlewares...)
 }
@@ -100,6 +143,58 @@ func (group *Group) Options(path string, handler any, middlewares ...any) contra
 	return group.Add(echo.OPTIONS, path, handler, middlewares...)
 }
 
+// Fallback 注册组的 fallback 路由，组前缀（以及组的域名）下没有任何路由匹配并且没有其他请求方法能匹配时使用，继承组的中间件
+func (group *Group) Fallback(handler any, middlewares ...any) contracts.RouteGroup {
+	group.fallbacks = append(group.fallbacks, group.newPrefixRoute(append([]string{}, methodList[:]...), "", handler, middlewares...))
+	return group
+}
+
+// Fallbacks 返回组以及所有子组的 fallback 路由
+func (group *Group) Fallbacks() []contracts.Route {
+	routes := append([]contracts.Route{}, group.fallbacks...)
+	for _, subGroup := range group.groups {
+		if groupInstance, isGroup := subGroup.(*Group); isGroup {
+			routes = append(routes, groupInstance.Fallbacks()...)
+		}
+	}
+	return routes
+}
+
+// addPrefixRoute 在组内注册一个前缀路由，组前缀加 path 开头的请求都会匹配该路由
+func (group *Group) addPrefixRoute(methods []string, path string, handler any, middlewares ...any) contracts.Route {
+	route := group.newPrefixRoute(methods, path, handler, middlewares...)
+	group.prefixRoutes = append(group.prefixRoutes, route)
+	return route
+}
+
+func (group *Group) newPrefixRoute(methods []string, path string, handler any, middlewares ...any) contracts.Route {
+	prefix := trimPath(group.prefix + path)
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	middlewares, excluded := splitExclusions(middlewares)
+	return &Route{
+		method:              methods,
+		path:                prefix,
+		middlewares:         mergeMiddlewares(group.middlewares, ConvertToMiddlewares(middlewares...)),
+		handler:             toHandler(handler),
+		group:               group,
+		inherited:           len(group.middlewares),
+		excludedMiddlewares: excluded,
+	}
+}
+
+// PrefixRoutes 返回组以及所有子组的前缀路由，例如静态文件路由
+func (group *Group) PrefixRoutes() []contracts.Route {
+	routes := append([]contracts.Route{}, group.prefixRoutes...)
+	for _, subGroup := range group.groups {
+		if groupInstance, isGroup := subGroup.(*Group); isGroup {
+			routes = append(routes, groupInstance.PrefixRoutes()...)
+		}
+	}
+	return routes
+}
+
 func (group *Group) Routes() []contracts.Route {
 	routes := group.routes
 
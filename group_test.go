@@ -0,0 +1,86 @@
+package routing_test
+
+import (
+	"fmt"
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/routing"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newMiddleware() contracts.MagicalFunc {
+	return container.NewMagicalFunc(func(next contracts.Pipe) any { return next(nil) })
+}
+
+func TestGroupMiddlewaresAreIsolated(t *testing.T) {
+	parentMiddlewares := []any{newMiddleware(), newMiddleware(), newMiddleware()}
+	parent := routing.NewGroup("/parent", parentMiddlewares...)
+
+	expected := map[string][]any{}
+	for i := 0; i < 20; i++ {
+		groupMiddleware := newMiddleware()
+		group := parent.Group(fmt.Sprintf("/group%d", i), groupMiddleware)
+		for j := 0; j < 5; j++ {
+			routeMiddleware := newMiddleware()
+			path := fmt.Sprintf("/route%d", j)
+			group.Get(path, func() string { return "ok" }, routeMiddleware)
+			expected[fmt.Sprintf("/parent/group%d%s", i, path)] = append(append(append([]any{}, parentMiddlewares...), groupMiddleware), routeMiddleware)
+		}
+
+		routeMiddleware := newMiddleware()
+		parent.Get(fmt.Sprintf("/route%d", i), func() string { return "ok" }, routeMiddleware)
+		expected[fmt.Sprintf("/parent/route%d", i)] = append(append([]any{}, parentMiddlewares...), routeMiddleware)
+	}
+
+	routes := parent.Routes()
+	assert.Len(t, routes, len(expected))
+	for _, route := range routes {
+		middlewares := route.Middlewares()
+		declared := expected[route.GetPath()]
+		if assert.Len(t, middlewares, len(declared), route.GetPath()) {
+			for i, middleware := range middlewares {
+				assert.True(t, middleware == declared[i], route.GetPath())
+			}
+		}
+	}
+
+	// 修改返回的中间件不影响路由
+	middlewares := routes[0].Middlewares()
+	middlewares[0] = newMiddleware()
+	_ = append(middlewares[:1], newMiddleware())
+	assert.True(t, routes[0].Middlewares()[0] == parentMiddlewares[0])
+	assert.True(t, routes[0].Middlewares()[1] == parentMiddlewares[1])
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	route := router.Get("/users", func() string { return "users" }, "auth")
+	middlewares = route.Middlewares()
+	middlewares[0] = newMiddleware()
+	assert.NoError(t, router.Mount())
+	assert.Equal(t, "auth", routing.MiddlewareName(route.Middlewares()[0]))
+	assert.Equal(t, "auth", routing.MiddlewareName(router.RouteMiddlewares(route)[0]))
+}
+
+func TestGroupHostIsInherited(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	api := router.Group("/api").Host("{tenant}.example.com")
+	v1 := api.Group("/v1").(*routing.Group)
+	v1.Get("/users", func() string { return "users" })
+	v1.Group("/admin").Host("admin.example.com").Get("/stats", func() string { return "stats" })
+	v1.Get("/status", func() string { return "status" })
+	assert.Equal(t, "{tenant}.example.com", v1.GetHost())
+
+	routes := v1.Routes()
+	assert.Equal(t, "{tenant}.example.com", routes[0].GetHost())
+	assert.Equal(t, "admin.example.com", routes[2].GetHost())
+	routes[1].Host("status.example.com")
+	assert.Equal(t, "status.example.com", routes[1].GetHost())
+
+	assert.NoError(t, router.Mount())
+	_, params, err := router.Route(http.MethodGet, &url.URL{Host: "acme.example.com", Path: "/api/v1/users"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", params["tenant"])
+}
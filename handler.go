@@ -0,0 +1,169 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"net/http"
+	"strings"
+)
+
+// Renderer 把处理器的返回值写入响应，返回 false 表示不处理该类型的返回值
+type Renderer func(w http.ResponseWriter, r *http.Request, result any) bool
+
+// Handler 把 HttpRouter 适配为 http.Handler，路由、执行中间件和处理器，并渲染返回值
+type Handler struct {
+	app       contracts.Container
+	router    contracts.HttpRouter
+	renderers []Renderer
+}
+
+// NewHandler 创建一个 http.Handler，中间件和处理器通过 app 调用，
+// 调用时可以注入 *http.Request、http.ResponseWriter、contracts.RouteParams，中间件还可以注入 contracts.Pipe
+func NewHandler(app contracts.Container, router contracts.HttpRouter, renderers ...Renderer) *Handler {
+	return &Handler{
+		app:       app,
+		router:    router,
+		renderers: append(append([]Renderer{}, renderers...), defaultRenderers...),
+	}
+}
+
+// Render 添加一个渲染器，后添加的渲染器优先于已有的渲染器
+func (handler *Handler) Render(renderer Renderer) *Handler {
+	handler.renderers = append([]Renderer{renderer}, handler.renderers...)
+	return handler
+}
+
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, params, err := handler.router.Route(r.Method, r.URL)
+	if r.Method == http.MethodHead && errors.Is(err, MethodNotAllowErr) {
+		// 没有注册 HEAD 的路由按 GET 处理，http.Server 不会输出 HEAD 请求的响应体
+		route, params, err = handler.router.Route(http.MethodGet, r.URL)
+	}
+	var redirect *RedirectError
+	switch {
+	case errors.As(err, &redirect):
+		http.Redirect(w, r, redirect.Location, redirect.Status)
+		return
+	case errors.Is(err, MethodNotAllowErr):
+		if allowed := allowedMethods(handler.router, r); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	case err != nil:
+		http.NotFound(w, r)
+		return
+	}
+	if params == nil {
+		params = contracts.RouteParams{}
+	}
+
+	pipeline := NewRoutePipeline(handler.app, handler.router, route)
+	// 请求同时作为注入参数传入，中间件传给下一层的值不影响处理器注入 *http.Request
+	result := pipeline.Then(r, route.Handler(), r, w, params)
+
+	handler.render(w, r, result)
+	pipeline.Terminate(r, result, w, params)
+}
+
+func (handler *Handler) render(w http.ResponseWriter, r *http.Request, result any) {
+	for _, renderer := range handler.renderers {
+		if renderer(w, r, result) {
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("unsupported response type %T", result), http.StatusInternalServerError)
+}
+
+// allowedMethods 返回能匹配当前请求路径的请求方法，允许 GET 时也允许 HEAD
+func allowedMethods(router contracts.HttpRouter, r *http.Request) []string {
+	var allowed []string
+	if httpRouter, isHttpRouter := router.(*HttpRouter); isHttpRouter {
+		allowed = httpRouter.AllowedMethods(r.URL)
+	} else {
+		for _, method := range methodList {
+			if route, _, err := router.Route(method, r.URL); err == nil && route != nil {
+				allowed = append(allowed, method)
+			}
+		}
+	}
+
+	var hasGet, hasHead bool
+	for _, method := range allowed {
+		hasGet = hasGet || method == http.MethodGet
+		hasHead = hasHead || method == http.MethodHead
+	}
+	if hasGet && !hasHead {
+		allowed = append(allowed, http.MethodHead)
+	}
+	return allowed
+}
+
+var defaultRenderers = []Renderer{
+	RenderNil,
+	RenderHandler,
+	RenderError,
+	RenderString,
+	RenderJson,
+}
+
+// RenderNil 没有返回值时响应 204
+func RenderNil(w http.ResponseWriter, _ *http.Request, result any) bool {
+	if result != nil {
+		return false
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// RenderHandler 返回值本身是 http.Handler 时交给它处理
+func RenderHandler(w http.ResponseWriter, r *http.Request, result any) bool {
+	if h, ok := result.(http.Handler); ok {
+		h.ServeHTTP(w, r)
+		return true
+	}
+	return false
+}
+
+// RenderError 返回 error 时响应 500，NotImplementedErr 响应 501
+func RenderError(w http.ResponseWriter, _ *http.Request, result any) bool {
+	if err, ok := result.(error); ok {
+		status := http.StatusInternalServerError
+		if errors.Is(err, NotImplementedErr) {
+			status = http.StatusNotImplemented
+		}
+		http.Error(w, err.Error(), status)
+		return true
+	}
+	return false
+}
+
+// RenderString 字符串和字节切片按纯文本输出
+func RenderString(w http.ResponseWriter, _ *http.Request, result any) bool {
+	switch value := result.(type) {
+	case string:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(value))
+	case []byte:
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		_, _ = w.Write(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// RenderJson 其他返回值按 json 输出
+func RenderJson(w http.ResponseWriter, _ *http.Request, result any) bool {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(body)
+	return true
+}
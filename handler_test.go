@@ -0,0 +1,314 @@
+package routing_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/routing"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	router.Get("/users/{id}", func(params contracts.RouteParams) any {
+		return map[string]string{"id": params["id"]}
+	}, func(request *http.Request, next contracts.Pipe) any {
+		if request.URL.Query().Get("token") == "" {
+			return "unauthorized"
+		}
+		return next(request)
+	})
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(container.New(), router)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1?token=x", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"id":"1"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal(t, "unauthorized", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, HEAD", recorder.Header().Get("Allow"))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/users/1?token=x", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestHandlerInjectsRequest(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	router.Get("/users/{id}", func(request *http.Request, params contracts.RouteParams) string {
+		return request.Method + " " + params["id"]
+	}, func(next contracts.Pipe) any {
+		return next(nil)
+	})
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(container.New(), router)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal(t, "GET 1", recorder.Body.String())
+
+	handler = routing.NewHandler(nil, router)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal(t, "GET 1", recorder.Body.String())
+}
+
+func TestHandlerPrefix(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	router.Get("/admin/login", func() string { return "login" })
+	router.(*routing.HttpRouter).Prefix("/admin", func(request *http.Request, params contracts.RouteParams) string {
+		return request.Method + " " + params[routing.RemainderParam]
+	})
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(container.New(), router)
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/admin/anything/deep", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code, method)
+		assert.Equal(t, method+" /anything/deep", recorder.Body.String(), method)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/login", nil))
+	assert.Equal(t, "POST /login", recorder.Body.String())
+}
+
+func TestHandlerNotImplemented(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	router.Get("/users", func() any { return fmt.Errorf("%w: users.index", routing.NotImplementedErr) })
+	router.Get("/fail", func() any { return errors.New("failed") })
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(container.New(), router)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestHandlerRenderersAreCopied(t *testing.T) {
+	router := routing.NewHttpRouter(nil)
+	router.Get("/", func() any { return 1 })
+	router.Get("/name", func() string { return "name" })
+	assert.NoError(t, router.Mount())
+
+	renderers := make([]routing.Renderer, 1, 8)
+	renderers[0] = func(w http.ResponseWriter, _ *http.Request, result any) bool {
+		if result == 1 {
+			_, _ = w.Write([]byte("one"))
+			return true
+		}
+		return false
+	}
+	first := routing.NewHandler(container.New(), router, renderers...)
+	second := routing.NewHandler(container.New(), router, append(renderers, func(w http.ResponseWriter, _ *http.Request, _ any) bool {
+		_, _ = w.Write([]byte("second"))
+		return true
+	})...)
+
+	recorder := httptest.NewRecorder()
+	first.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/name", nil))
+	assert.Equal(t, "name", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	second.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/name", nil))
+	assert.Equal(t, "second", recorder.Body.String())
+}
+
+func TestHandlerRedirect(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/articles/{slug}", func() string { return "article" }).Name("articles.show")
+	router.PermanentRedirect("/blog/{slug}", "/articles/{slug}")
+	router.Redirect("/posts/{slug}", "articles.show", 0)
+	assert.NoError(t, router.ImportRedirects(strings.NewReader("/old-pricing,/pricing,308\n"), "csv"))
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(container.New(), router)
+	for path, expected := range map[string]struct {
+		status   int
+		location string
+	}{
+		"/blog/hello":           {http.StatusMovedPermanently, "/articles/hello"},
+		"/blog/hello?ref=feed":  {http.StatusMovedPermanently, "/articles/hello?ref=feed"},
+		"/blog/hello%20world":   {http.StatusMovedPermanently, "/articles/hello%20world"},
+		"/posts/a%3Fb":          {http.StatusFound, "/articles/a%3Fb"},
+		"/old-pricing":          {http.StatusPermanentRedirect, "/pricing"},
+		"/old-pricing?plan=pro": {http.StatusPermanentRedirect, "/pricing?plan=pro"},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, expected.status, recorder.Code, path)
+		assert.Equal(t, expected.location, recorder.Header().Get("Location"), path)
+	}
+}
+
+func TestHandlerRedirectStatus(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	assert.PanicsWithError(t, "invalid redirect status: 200", func() {
+		router.Redirect("/old", "/new", http.StatusOK)
+	})
+
+	err := router.ImportRedirects(strings.NewReader("/a,/b,301\n/old,/new,404\n"), "csv")
+	assert.ErrorIs(t, err, routing.InvalidRedirectStatusErr)
+	assert.NoError(t, router.Mount())
+	_, _, err = router.Route(http.MethodGet, &url.URL{Path: "/a"})
+	assert.ErrorIs(t, err, routing.NotFoundErr)
+}
+
+func TestHandlerStatic(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":     {Data: []byte("<html>app</html>"), ModTime: time.Unix(1700000000, 0)},
+		"app.js":         {Data: []byte("console.log(1)")},
+		"app.js.gz":      {Data: []byte("gzipped")},
+		"docs/guide.txt": {Data: []byte("guide")},
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Static("/assets", files)
+	router.Group("/app").(*routing.Group).Static("", files, routing.StaticSPA())
+	assert.NoError(t, router.Mount())
+	handler := routing.NewHandler(container.New(), router)
+
+	serve := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve("/assets", nil)
+	assert.Equal(t, "<html>app</html>", recorder.Body.String())
+	assert.NotEmpty(t, recorder.Header().Get("ETag"))
+	assert.NotEmpty(t, recorder.Header().Get("Last-Modified"))
+
+	assert.Equal(t, http.StatusNotModified, serve("/assets/index.html", map[string]string{
+		"If-None-Match": recorder.Header().Get("ETag"),
+	}).Code)
+
+	recorder = serve("/assets/app.js", map[string]string{"Accept-Encoding": "br, gzip"})
+	assert.Equal(t, "gzipped", recorder.Body.String())
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+
+	assert.Equal(t, http.StatusNotFound, serve("/assets/docs", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve("/assets/missing.js", nil).Code)
+	assert.Equal(t, "<html>app</html>", serve("/app/users/1", nil).Body.String())
+	assert.Equal(t, "guide", serve("/app/docs/guide.txt", nil).Body.String())
+}
+
+func TestHandlerStaticZeroModTime(t *testing.T) {
+	// embed.FS 的文件没有修改时间，大小相同、内容不同的文件也不能得到相同的 ETag
+	files := fstest.MapFS{
+		"a.txt": {Data: []byte("aaaa")},
+		"b.txt": {Data: []byte("bbbb")},
+	}
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Static("/assets", files)
+	router.Static("/built", files, routing.StaticModTime(modTime))
+	assert.NoError(t, router.Mount())
+	handler := routing.NewHandler(container.New(), router)
+
+	serve := func(path, etag string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	a, b := serve("/assets/a.txt", ""), serve("/assets/b.txt", "")
+	assert.Equal(t, "aaaa", a.Body.String())
+	assert.Equal(t, "bbbb", b.Body.String())
+	assert.NotEmpty(t, a.Header().Get("ETag"))
+	assert.NotEqual(t, a.Header().Get("ETag"), b.Header().Get("ETag"))
+	assert.Empty(t, a.Header().Get("Last-Modified"))
+
+	assert.Equal(t, http.StatusNotModified, serve("/assets/a.txt", a.Header().Get("ETag")).Code)
+	recorder := serve("/assets/b.txt", a.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "bbbb", recorder.Body.String())
+
+	recorder = serve("/built/a.txt", "")
+	assert.Equal(t, a.Header().Get("ETag"), recorder.Header().Get("ETag"))
+	assert.Equal(t, modTime.Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+}
+
+func TestHandlerStaticPrecompressed(t *testing.T) {
+	files := fstest.MapFS{
+		"app.js":    {Data: []byte("console.log(1)")},
+		"app.js.gz": {Data: []byte("gzipped")},
+		"app.js.br": {Data: []byte("brotli")},
+		"app.css":   {Data: []byte("body{}")},
+		"lib.js":    {Data: []byte("lib")},
+		"lib.js.gz": {Data: []byte("gzipped lib")},
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Static("/assets", files)
+	router.Static("/plain", files, routing.StaticPrecompressed(false))
+	assert.NoError(t, router.Mount())
+	handler := routing.NewHandler(container.New(), router)
+
+	etags := map[string]bool{}
+	for _, item := range []struct {
+		path, acceptEncoding, body, contentEncoding string
+	}{
+		{"/assets/app.js", "gzip, br", "brotli", "br"},
+		{"/assets/app.js", "gzip", "gzipped", "gzip"},
+		{"/assets/app.js", "br;q=0, gzip", "gzipped", "gzip"},
+		{"/assets/app.js", "", "console.log(1)", ""},
+		{"/assets/lib.js", "br, gzip", "gzipped lib", "gzip"},
+		{"/assets/app.css", "br, gzip", "body{}", ""},
+		{"/plain/app.js", "br, gzip", "console.log(1)", ""},
+	} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, item.path, nil)
+		request.Header.Set("Accept-Encoding", item.acceptEncoding)
+		handler.ServeHTTP(recorder, request)
+
+		message := item.path + " " + item.acceptEncoding
+		assert.Equal(t, http.StatusOK, recorder.Code, message)
+		assert.Equal(t, item.body, recorder.Body.String(), message)
+		assert.Equal(t, item.contentEncoding, recorder.Header().Get("Content-Encoding"), message)
+		etags[recorder.Header().Get("ETag")] = true
+	}
+	// 每个变体按内容计算 ETag，同一个文件的不同编码不会共用 ETag
+	assert.Len(t, etags, 5)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	request.Header.Set("Accept-Encoding", "br")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+}
@@ -3,15 +3,16 @@ package routing
 import (
 	"errors"
 	"fmt"
-	"github.com/goal-web/container"
 	"github.com/goal-web/contracts"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 )
 
 var (
-	MiddlewareError = errors.New("middleware error") // 中间件必须有一个返回值
+	MiddlewareError         = errors.New("middleware error")                 // 中间件必须有一个返回值
+	ExcludedMiddlewareError = errors.New("excluded middleware type unknown") // 排除的中间件只能是别名、中间件实例或者函数
 )
 
 var (
@@ -28,104 +29,319 @@ var (
 	}
 )
 
+// RemainderParam 前缀路由命中时，前缀之后剩余的路径存放在路由参数的这个键中
+const RemainderParam = "*"
+
 type HttpRouter struct {
 	app          contracts.Application
 	groups       []contracts.RouteGroup
 	routes       []contracts.Route
 	routers      map[string]contracts.Router[contracts.Route]
-	hostsRouters contracts.Router[map[string]contracts.Router[contracts.Route]]
+	hostsRouters contracts.Router[string] // 域名模板到域名的路由器，命中后在 hostRouters 中查找该域名下的路由器
+
+	// 前缀路由，用于挂载子应用、反向代理等，与普通路由一起参与请求方法的匹配
+	prefixRoutes []contracts.Route
+	prefixes     *prefixTable
+
+	// fallback 路由，只在没有任何请求方法能匹配时使用
+	fallbackRoutes []contracts.Route
+	fallbacks      *prefixTable
+
+	// 挂载在指定前缀下的子路由器，HttpRouter 会被合并，其他实现在 Route 时委托查找
+	mountedRouters []*mountedRouter
+	delegates      []*mountedRouter
 
 	// 全局中间件
 	middlewares []contracts.MagicalFunc
+
+	// 中间件别名和中间件组，Mount 时解析字符串引用
+	middlewareAliases map[string]contracts.MagicalFunc
+	middlewareGroups  map[string][]contracts.MagicalFunc
+
+	// 中间件优先级，Mount 时按此顺序调整每个路由的中间件
+	middlewarePriority []any
+
+	// 路由名索引以及挂载的路由，Mount 时建立
+	names          map[string]contracts.Route
+	mountedEntries []routeEntry
+
+	// 按域名分组的路由器，Mount 时建立，生成路由缓存时使用
+	hostRouters map[string]map[string]contracts.Router[contracts.Route]
+
+	// 契约优先模式下的 OpenAPI 文档
+	contract *openAPIContract
+
+	// 创建路径路由器、前缀路由器和域名路由器
+	routerFactory       RouterFactory
+	prefixRouterFactory PrefixRouterFactory
+	hostRouterFactory   HostRouterFactory
+
+	// 路径匹配选项，参考 WithStrictSlash、WithCaseInsensitive、WithCleanPath、WithRedirectTrailingSlash 和 WithCanonicalRedirect
+	strictSlash           bool
+	caseInsensitive       bool
+	cleanPath             bool
+	redirectTrailingSlash bool
+	canonicalRedirect     bool
 }
 
-func NewHttpRouter(app contracts.Application) contracts.HttpRouter {
+func NewHttpRouter(app contracts.Application, options ...HttpRouterOption) contracts.HttpRouter {
 	router := &HttpRouter{
-		app:          app,
-		routes:       make([]contracts.Route, 0),
-		groups:       make([]contracts.RouteGroup, 0),
-		middlewares:  make([]contracts.MagicalFunc, 0),
-		routers:      map[string]contracts.Router[contracts.Route]{},
-		hostsRouters: NewRouter[map[string]contracts.Router[contracts.Route]](),
+		app:         app,
+		routes:      make([]contracts.Route, 0),
+		groups:      make([]contracts.RouteGroup, 0),
+		middlewares: make([]contracts.MagicalFunc, 0),
+		routers:     map[string]contracts.Router[contracts.Route]{},
+
+		prefixRoutes:      make([]contracts.Route, 0),
+		fallbackRoutes:    make([]contracts.Route, 0),
+		mountedRouters:    make([]*mountedRouter, 0),
+		middlewareAliases: map[string]contracts.MagicalFunc{},
+		middlewareGroups:  map[string][]contracts.MagicalFunc{},
+
+		hostRouterFactory: NewRouter[string],
+	}
+
+	for _, option := range options {
+		option(router)
+	}
+	if router.routerFactory == nil {
+		router.routerFactory = router.defaultRouter
 	}
+	if router.prefixRouterFactory == nil {
+		router.prefixRouterFactory = router.defaultPrefixRouter
+	}
+	router.hostsRouters = router.hostRouterFactory()
+	router.prefixes = router.newPrefixTable()
+	router.fallbacks = router.newPrefixTable()
 
 	return router
 }
 
-func (httpRouter *HttpRouter) addHostRoute(hostRouters map[string]map[string]contracts.Router[contracts.Route], route contracts.Route) []string {
-	if host := route.GetHost(); host != "" {
+func (httpRouter *HttpRouter) addHostRoute(hostRouters map[string]map[string]contracts.Router[contracts.Route], entry routeEntry) []string {
+	if host := entry.route.GetHost(); host != "" {
 		if hostRouters[host] == nil {
 			hostRouters[host] = map[string]contracts.Router[contracts.Route]{}
 		}
 
-		return httpRouter.addRoute(hostRouters[host], route)
+		return httpRouter.addRoute(hostRouters[host], entry)
 	}
 
 	return nil
 }
 
-func (httpRouter *HttpRouter) addRoute(routers map[string]contracts.Router[contracts.Route], route contracts.Route) []string {
+func (httpRouter *HttpRouter) addRoute(routers map[string]contracts.Router[contracts.Route], entry routeEntry) []string {
 	var failedSignatures []string
-	for _, method := range route.Method() {
+	for _, method := range entry.route.Method() {
 		if routers[method] == nil {
-			routers[method] = NewRouter[contracts.Route]()
+			routers[method] = httpRouter.routerFactory()
+			httpRouter.configure(routers[method])
 		}
 
-		signature, err := routers[method].Add(route.GetPath(), route)
+		signature, err := routers[method].Add(entry.route.GetPath(), entry.route)
 		if err != nil {
-			failedSignatures = append(failedSignatures, fmt.Sprintf("[%s] %s", method, signature))
+			failedSignatures = append(failedSignatures, fmt.Sprintf("[%s] %s%s", method, signature, entry.describe()))
 		}
 	}
 	return failedSignatures
 }
 
-func (httpRouter *HttpRouter) Mount() error {
-	var failedSignatures []string
-	var hostRoutersMap = make(map[string]map[string]contracts.Router[contracts.Route])
+// addPrefixEntry 把前缀路由添加到 table 中，指定了域名的前缀路由添加到该域名下
+func (httpRouter *HttpRouter) addPrefixEntry(table *prefixTable, entry routeEntry) []string {
+	if host := entry.route.GetHost(); host != "" {
+		if table.hostRouters[host] == nil {
+			table.hostRouters[host] = map[string]PrefixRouter[contracts.Route]{}
+		}
+		return httpRouter.addPrefixRoute(table.hostRouters[host], entry)
+	}
+	return httpRouter.addPrefixRoute(table.routers, entry)
+}
 
-	for _, route := range httpRouter.routes {
-		tmpFailedSignatures := httpRouter.addRoute(httpRouter.routers, route)
-		if len(tmpFailedSignatures) > 0 {
-			failedSignatures = append(failedSignatures, tmpFailedSignatures...)
+func (httpRouter *HttpRouter) addPrefixRoute(routers map[string]PrefixRouter[contracts.Route], entry routeEntry) []string {
+	var failedSignatures []string
+	for _, method := range entry.route.Method() {
+		if routers[method] == nil {
+			routers[method] = httpRouter.prefixRouterFactory()
+			httpRouter.configure(routers[method])
 		}
 
-		tmpFailedSignatures = httpRouter.addHostRoute(hostRoutersMap, route)
-		if len(tmpFailedSignatures) > 0 {
-			failedSignatures = append(failedSignatures, tmpFailedSignatures...)
+		signature, err := routers[method].Add(entry.route.GetPath(), entry.route)
+		if err != nil {
+			failedSignatures = append(failedSignatures, fmt.Sprintf("[%s] %s*%s", method, signature, entry.describe()))
 		}
 	}
+	return failedSignatures
+}
+
+// routeEntry 待挂载的路由，origin 记录路由的来源，用于冲突时定位；fallback 路由同时也是前缀路由
+type routeEntry struct {
+	route    contracts.Route
+	prefix   bool
+	fallback bool
+	origin   string
+}
 
+func (entry routeEntry) describe() string {
+	if entry.origin == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", entry.origin)
+}
+
+// entries 返回直接注册的路由、所有组内的路由、前缀路由、fallback 路由以及合并进来的子路由器的路由
+func (httpRouter *HttpRouter) entries() []routeEntry {
+	var entries []routeEntry
+	for _, route := range httpRouter.routes {
+		entries = append(entries, routeEntry{route: route})
+	}
 	for _, group := range httpRouter.groups {
 		for _, route := range group.Routes() {
-			tmpFailedSignatures := httpRouter.addRoute(httpRouter.routers, route)
-			if len(tmpFailedSignatures) > 0 {
-				failedSignatures = append(failedSignatures, tmpFailedSignatures...)
+			entries = append(entries, routeEntry{route: route})
+		}
+	}
+	for _, route := range httpRouter.prefixRoutes {
+		entries = append(entries, routeEntry{route: route, prefix: true})
+	}
+	for _, group := range httpRouter.groups {
+		if groupInstance, isGroup := group.(*Group); isGroup {
+			for _, route := range groupInstance.PrefixRoutes() {
+				entries = append(entries, routeEntry{route: route, prefix: true})
 			}
-			tmpFailedSignatures = httpRouter.addHostRoute(hostRoutersMap, route)
-			if len(tmpFailedSignatures) > 0 {
-				failedSignatures = append(failedSignatures, tmpFailedSignatures...)
+		}
+	}
+	for _, route := range httpRouter.fallbackRoutes {
+		entries = append(entries, routeEntry{route: route, prefix: true, fallback: true})
+	}
+	for _, group := range httpRouter.groups {
+		if groupInstance, isGroup := group.(*Group); isGroup {
+			for _, route := range groupInstance.Fallbacks() {
+				entries = append(entries, routeEntry{route: route, prefix: true, fallback: true})
 			}
 		}
 	}
+	// 从路由配置注册的路由记录了所在的文件和行号
+	for i := range entries {
+		if route, isRoute := entries[i].route.(*Route); isRoute {
+			entries[i].origin = route.origin
+		}
+	}
+	for _, mounted := range httpRouter.mountedRouters {
+		entries = append(entries, mounted.entries()...)
+	}
+	return entries
+}
+
+func (httpRouter *HttpRouter) Mount() error {
+	entries, unknownMiddlewares := httpRouter.prepare()
+	failedSignatures := httpRouter.build(entries)
+	return httpRouter.finish(entries, unknownMiddlewares, failedSignatures)
+}
 
-	if len(hostRoutersMap) > 0 {
-		httpRouter.hostsRouters = NewRouter[map[string]contracts.Router[contracts.Route]]()
-		for host, router := range hostRoutersMap {
-			signature, err := httpRouter.hostsRouters.Add(host, router)
+// prepare 收集待挂载的路由，解析中间件并建立路由名索引，返回未知的中间件
+func (httpRouter *HttpRouter) prepare() ([]routeEntry, []string) {
+	var entries = httpRouter.entries()
+	var unknownMiddlewares = httpRouter.resolveEntries(entries)
+
+	httpRouter.mountedEntries = entries
+	httpRouter.names = map[string]contracts.Route{}
+	for _, entry := range entries {
+		if name := entry.route.GetName(); name != "" {
+			httpRouter.names[name] = entry.route
+		}
+	}
+	return entries, unknownMiddlewares
+}
+
+// build 把路由添加到各个路由树中，返回冲突的路由签名
+func (httpRouter *HttpRouter) build(entries []routeEntry) []string {
+	var failedSignatures []string
+	httpRouter.hostRouters = make(map[string]map[string]contracts.Router[contracts.Route])
+	httpRouter.prefixes.hostRouters = make(map[string]map[string]PrefixRouter[contracts.Route])
+	httpRouter.fallbacks.hostRouters = make(map[string]map[string]PrefixRouter[contracts.Route])
+
+	for _, entry := range entries {
+		switch {
+		case entry.fallback:
+			failedSignatures = append(failedSignatures, httpRouter.addPrefixEntry(httpRouter.fallbacks, entry)...)
+		case entry.prefix:
+			failedSignatures = append(failedSignatures, httpRouter.addPrefixEntry(httpRouter.prefixes, entry)...)
+		default:
+			failedSignatures = append(failedSignatures, httpRouter.addRoute(httpRouter.routers, entry)...)
+			failedSignatures = append(failedSignatures, httpRouter.addHostRoute(httpRouter.hostRouters, entry)...)
+		}
+	}
+
+	return append(failedSignatures, httpRouter.buildHosts()...)
+}
+
+// buildHosts 根据按域名分组的路由器建立域名路由树
+func (httpRouter *HttpRouter) buildHosts() []string {
+	var failedSignatures []string
+	if len(httpRouter.hostRouters) > 0 {
+		httpRouter.hostsRouters = httpRouter.hostRouterFactory()
+		for host := range httpRouter.hostRouters {
+			signature, err := httpRouter.hostsRouters.Add(host, host)
 			if err != nil {
 				failedSignatures = append(failedSignatures, signature)
 			}
 		}
 	}
 
+	failedSignatures = append(failedSignatures, httpRouter.buildPrefixHosts(httpRouter.prefixes)...)
+	return append(failedSignatures, httpRouter.buildPrefixHosts(httpRouter.fallbacks)...)
+}
+
+// buildPrefixHosts 根据 table 中按域名分组的前缀路由器建立域名路由树
+func (httpRouter *HttpRouter) buildPrefixHosts(table *prefixTable) []string {
+	var failedSignatures []string
+	if len(table.hostRouters) > 0 {
+		table.hosts = httpRouter.hostRouterFactory()
+		for host := range table.hostRouters {
+			signature, err := table.hosts.Add(host, host)
+			if err != nil {
+				failedSignatures = append(failedSignatures, signature)
+			}
+		}
+	}
+	return failedSignatures
+}
+
+// finish 挂载委托的子路由器，校验契约并汇总挂载过程中的错误
+func (httpRouter *HttpRouter) finish(entries []routeEntry, unknownMiddlewares, failedSignatures []string) error {
+	var mountErrors []string
+	httpRouter.delegates = make([]*mountedRouter, 0)
+	for _, mounted := range httpRouter.mountedRouters {
+		if _, isMerged := mounted.router.(*HttpRouter); isMerged {
+			continue
+		}
+		if err := mounted.router.Mount(); err != nil {
+			mountErrors = append(mountErrors, fmt.Sprintf("%s: %s", mounted.origin(), err.Error()))
+		}
+		httpRouter.delegates = append(httpRouter.delegates, mounted)
+	}
+	sort.SliceStable(httpRouter.delegates, func(i, j int) bool {
+		return len(httpRouter.delegates[i].prefix) > len(httpRouter.delegates[j].prefix)
+	})
+
+	if httpRouter.contract != nil {
+		mountErrors = append(httpRouter.contract.validate(entries), mountErrors...)
+	}
+	mountErrors = append(httpRouter.routerOptionErrors(), mountErrors...)
+	if len(unknownMiddlewares) > 0 {
+		mountErrors = append([]string{unknownMiddlewareError(unknownMiddlewares).Error()}, mountErrors...)
+	}
 	if len(failedSignatures) > 0 {
-		return fmt.Errorf("duplicate route [%s] occurred", strings.Join(failedSignatures, "|"))
+		mountErrors = append([]string{
+			fmt.Sprintf("duplicate route [%s] occurred", strings.Join(failedSignatures, "|")),
+		}, mountErrors...)
+	}
+	if len(mountErrors) > 0 {
+		return errors.New(strings.Join(mountErrors, "; "))
 	}
 	return nil
 }
 
 func (httpRouter *HttpRouter) Add(method any, path string, handler any, middlewares ...any) contracts.Route {
-	if strings.HasSuffix(path, "/") && path != "/" {
+	if strings.HasSuffix(path, "/") && path != "/" && !httpRouter.strictSlash {
 		path = path[:len(path)-1]
 	}
 	if !strings.HasPrefix(path, "/") {
@@ -139,12 +355,35 @@ func (httpRouter *HttpRouter) Add(method any, path string, handler any, middlewa
 	case []string:
 		methods = v
 	}
-	route := NewRoute(methods, path, ConvertToMiddlewares(middlewares...), container.NewMagicalFunc(handler))
+	route := newRoute(methods, path, handler, middlewares)
 	httpRouter.routes = append(httpRouter.routes, route)
 	return route
 }
 
+// Prefix 注册一个前缀路由，所有请求方法下以 prefix 开头且没有被其他路由匹配的请求都交给 handler 处理，
+// 剩余的路径可以通过路由参数 RemainderParam 获取，例如 /admin 前缀下的 /admin/anything/deep 剩余 /anything/deep
+func (httpRouter *HttpRouter) Prefix(prefix string, handler any, middlewares ...any) contracts.Route {
+	return httpRouter.registerPrefixRoute(append([]string{}, methodList[:]...), prefix, handler, middlewares...)
+}
+
+func (httpRouter *HttpRouter) registerPrefixRoute(methods []string, prefix string, handler any, middlewares ...any) contracts.Route {
+	prefix = trimPath(prefix)
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	route := newRoute(methods, prefix, handler, middlewares)
+	httpRouter.prefixRoutes = append(httpRouter.prefixRoutes, route)
+	return route
+}
+
 func (httpRouter *HttpRouter) Route(method string, url *url.URL) (contracts.Route, contracts.RouteParams, error) {
+	if httpRouter.canonicalRedirect {
+		if route, params, err := httpRouter.canonicalPathRedirect(method, url); err != nil {
+			return route, params, err
+		}
+	}
+
 	route, params, err := httpRouter.route(method, url)
 	if err == nil {
 		return route, params, nil
@@ -158,21 +397,47 @@ func (httpRouter *HttpRouter) Route(method string, url *url.URL) (contracts.Rout
 			return route, params, MethodNotAllowErr
 		}
 	}
+
+	if httpRouter.redirectTrailingSlash || httpRouter.canonicalRedirect {
+		if route, params, err = httpRouter.trailingSlashRedirect(method, url); err != nil {
+			return route, params, err
+		}
+	}
+
+	// fallback 只在没有任何请求方法能匹配时使用
+	if route, params, err = httpRouter.routePrefix(httpRouter.fallbacks, method, url); err == nil {
+		return route, params, nil
+	}
 	return nil, nil, NotFoundErr
 }
 
+// AllowedMethods 返回能匹配 url 的请求方法，包含前缀路由，不包含 fallback
+func (httpRouter *HttpRouter) AllowedMethods(url *url.URL) []string {
+	var allowed []string
+	for _, method := range methodList {
+		if _, _, err := httpRouter.route(method, url); err == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
 func (httpRouter *HttpRouter) route(method string, url *url.URL) (contracts.Route, contracts.RouteParams, error) {
-	path := url.Path
-	if strings.HasSuffix(path, "/") && path != "/" {
-		path = path[:len(path)-1]
+	original := httpRouter.requestPath(url)
+	path := original
+	if !httpRouter.strictSlash {
+		path = trimPath(path)
 	}
 
 	if !httpRouter.hostsRouters.IsEmpty() {
-		routers, hostParams, hostErr := httpRouter.hostsRouters.Find(url.Host)
+		host, hostParams, hostErr := httpRouter.hostsRouters.Find(url.Host)
 		if hostErr == nil {
-			if routers[method] != nil {
+			if routers := httpRouter.hostRouters[host]; routers[method] != nil {
 				route, params, err := routers[method].Find(path)
-				if err == nil {
+				if err == nil && httpRouter.matchesSlash(route, original) {
+					if params == nil {
+						params = contracts.RouteParams{}
+					}
 					for key, value := range hostParams {
 						params[key] = value
 					}
@@ -183,20 +448,89 @@ func (httpRouter *HttpRouter) route(method string, url *url.URL) (contracts.Rout
 	}
 
 	router := httpRouter.routers[method]
+	if router != nil {
+		route, params, err := router.Find(path)
+		if err == nil && httpRouter.matchesSlash(route, original) {
+			return route, params, nil
+		}
+	}
+
+	for _, mounted := range httpRouter.delegates {
+		if route, params, err := mounted.route(method, url, path, httpRouter.caseInsensitive); err == nil {
+			return route, params, nil
+		}
+	}
+
+	return httpRouter.routePrefix(httpRouter.prefixes, method, url)
+}
+
+// prefixTable 按请求方法划分的前缀路由器，指定域名的前缀路由优先于不限域名的前缀路由
+type prefixTable struct {
+	routers     map[string]PrefixRouter[contracts.Route]
+	hostRouters map[string]map[string]PrefixRouter[contracts.Route]
+	hosts       contracts.Router[string] // 域名模板到域名的路由器，命中后在 hostRouters 中查找该域名下的前缀路由器
+}
+
+func (httpRouter *HttpRouter) newPrefixTable() *prefixTable {
+	return &prefixTable{
+		routers:     map[string]PrefixRouter[contracts.Route]{},
+		hostRouters: map[string]map[string]PrefixRouter[contracts.Route]{},
+		hosts:       httpRouter.hostRouterFactory(),
+	}
+}
+
+// routePrefix 在 table 中按最长前缀查找前缀路由，优先匹配指定域名下的前缀路由
+func (httpRouter *HttpRouter) routePrefix(table *prefixTable, method string, url *url.URL) (contracts.Route, contracts.RouteParams, error) {
+	path := trimPath(httpRouter.requestPath(url))
+
+	if !table.hosts.IsEmpty() {
+		hostKey, hostParams, hostErr := table.hosts.Find(url.Host)
+		if routers := table.hostRouters[hostKey]; hostErr == nil && routers[method] != nil {
+			route, params, remainder, err := routers[method].FindPrefix(path)
+			if err == nil {
+				params = withRemainder(params, remainder)
+				for key, value := range hostParams {
+					params[key] = value
+				}
+				return route, params, nil
+			}
+		}
+	}
+
+	router := table.routers[method]
 	if router == nil {
 		return nil, nil, NotFoundErr
 	}
 
-	route, params, err := router.Find(path)
+	route, params, remainder, err := router.FindPrefix(path)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return route, params, err
+	return route, withRemainder(params, remainder), nil
+}
+
+// withRemainder 把剩余路径存入 RemainderParam
+func withRemainder(params contracts.RouteParams, remainder string) contracts.RouteParams {
+	if params == nil {
+		params = contracts.RouteParams{}
+	}
+	params[RemainderParam] = remainder
+	return params
+}
+
+// Fallback 注册全局 fallback 路由，没有任何路由（包括前缀路由）匹配并且没有其他请求方法能匹配时使用；
+// 组和域名下的 fallback 更具体，优先于全局 fallback
+func (httpRouter *HttpRouter) Fallback(handler any, middlewares ...any) contracts.Route {
+	route := newRoute(append([]string{}, methodList[:]...), "/", handler, middlewares)
+	httpRouter.fallbackRoutes = append(httpRouter.fallbackRoutes, route)
+	return route
 }
 
 func (httpRouter *HttpRouter) Group(prefix string, middlewares ...any) contracts.RouteGroup {
 	groupInstance := NewGroup(prefix, middlewares...)
+	groupInstance.(*Group).app = httpRouter.app
+	groupInstance.(*Group).router = httpRouter
 
 	httpRouter.groups = append(httpRouter.groups, groupInstance)
 
@@ -233,10 +567,12 @@ func (httpRouter *HttpRouter) Trace(path string, handler any, middlewares ...any
 
 func (httpRouter *HttpRouter) Use(middlewares ...any) {
 	for _, middleware := range middlewares {
-		if magicalFunc, ok := middleware.(contracts.MagicalFunc); ok {
+		if name, isReference := middleware.(string); isReference {
+			httpRouter.middlewares = append(httpRouter.middlewares, newMiddlewareReference(name))
+		} else if magicalFunc, ok := middleware.(contracts.MagicalFunc); ok {
 			httpRouter.middlewares = append(httpRouter.middlewares, magicalFunc)
 		} else {
-			httpRouter.middlewares = append(httpRouter.middlewares, container.NewMagicalFunc(middleware))
+			httpRouter.middlewares = append(httpRouter.middlewares, newMagicalFunc(middleware))
 		}
 	}
 }
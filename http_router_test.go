@@ -0,0 +1,1174 @@
+package routing_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/routing"
+	"github.com/goal-web/routing/routingtest"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHttpRouterMountRouter(t *testing.T) {
+	billing := routing.NewHttpRouter(nil)
+	billing.Get("/invoices/{id}", func() string { return "invoice" }).Name("invoices.show")
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.MountRouter("/billing", billing, func() any { return nil })
+	assert.NoError(t, router.Mount())
+
+	route, params, err := router.Route(http.MethodGet, &url.URL{Path: "/billing/invoices/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "billing.invoices.show", route.GetName())
+	assert.Equal(t, "/billing/invoices/{id}", route.GetPath())
+	assert.Equal(t, "1", params["id"])
+	assert.Len(t, route.Middlewares(), 1)
+}
+
+func TestHttpRouterMountRouterMiddlewaresAreIsolated(t *testing.T) {
+	billing := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	for _, name := range []string{"session", "auth", "log", "audit"} {
+		billing.AliasMiddleware(name, func(next contracts.Pipe) any { return next(nil) })
+	}
+	billing.Use("session")
+	billing.Get("/invoices", func() string { return "invoices" }, "auth")
+	billing.Get("/reports", func() string { return "reports" }, "audit")
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("log", func(next contracts.Pipe) any { return next(nil) })
+	router.MountRouter("/billing", billing, "log")
+	assert.NoError(t, router.Mount())
+
+	names, err := router.MiddlewareNames(http.MethodGet, "/billing/invoices")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log", "session", "auth"}, names)
+
+	names, err = router.MiddlewareNames(http.MethodGet, "/billing/reports")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log", "session", "audit"}, names)
+}
+
+func TestHttpRouterMountRouterConflict(t *testing.T) {
+	billing := routing.NewHttpRouter(nil)
+	billing.Get("/invoices", func() string { return "invoices" })
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/billing/invoices", func() string { return "invoices" })
+	router.MountRouter("/billing", billing)
+
+	err := router.Mount()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "router mounted at /billing")
+}
+
+func TestHttpRouterMiddlewareAliases(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("throttle", func(params routing.MiddlewareParams, next contracts.Pipe) any {
+		return strings.Join(params, ",")
+	})
+	router.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	router.MiddlewareGroup("api", "auth", "throttle:60,1")
+	router.Get("/users", func() string { return "users" }, "api")
+	assert.NoError(t, router.Mount())
+
+	route, _, err := router.Route(http.MethodGet, &url.URL{Path: "/users"})
+	assert.NoError(t, err)
+	assert.Len(t, route.Middlewares(), 2)
+	assert.Equal(t, "auth", routing.MiddlewareName(route.Middlewares()[0]))
+	assert.Equal(t, "throttle", routing.MiddlewareName(route.Middlewares()[1]))
+
+	result := routing.NewPipeline(nil).Through(route.Middlewares()[1]).Then(nil, route.Handler())
+	assert.Equal(t, "60,1", result)
+
+	router = routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/posts", func() string { return "posts" }, "session", "csrf")
+	err = router.Mount()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown middleware [csrf|session]")
+}
+
+func TestHttpRouterWithoutMiddleware(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	router.AliasMiddleware("session", func(next contracts.Pipe) any { return next(nil) })
+	router.Use("session")
+
+	group := router.Group("/app", "auth")
+	group.Get("/profile", func() string { return "profile" })
+	group.Group("/hooks").(*routing.Group).WithoutMiddleware("session").
+		Post("/github", func() string { return "ok" })
+	group.Add(http.MethodPost, "/stripe", func() string { return "ok" }, routing.Without("auth", "session"))
+	// 排除继承的中间件不影响路由自身声明的同名中间件
+	group.Add(http.MethodPost, "/paypal", func() string { return "ok" }, "auth", routing.Without("auth", "session"))
+	router.Group("/internal", routing.Without("session")).Get("/health", func() string { return "ok" })
+	assert.NoError(t, router.Mount())
+
+	names := func(path, method string) []string {
+		route, _, err := router.Route(method, &url.URL{Path: path})
+		assert.NoError(t, err)
+		var results []string
+		for _, middleware := range router.RouteMiddlewares(route) {
+			results = append(results, routing.MiddlewareName(middleware))
+		}
+		return results
+	}
+
+	assert.Equal(t, []string{"session", "auth"}, names("/app/profile", http.MethodGet))
+	assert.Equal(t, []string{"auth"}, names("/app/hooks/github", http.MethodPost))
+	assert.Nil(t, names("/app/stripe", http.MethodPost))
+	assert.Equal(t, []string{"auth"}, names("/app/paypal", http.MethodPost))
+	assert.Nil(t, names("/internal/health", http.MethodGet))
+}
+
+func TestHttpRouterRouteMiddlewaresStack(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	router.AliasMiddleware("session", func(next contracts.Pipe) any { return next(nil) })
+	router.MiddlewarePriority("session", "auth")
+	router.Use("auth", "session")
+	router.Get("/profile", func() string { return "profile" })
+	assert.NoError(t, router.Mount())
+
+	route, _, err := router.Route(http.MethodGet, &url.URL{Path: "/profile"})
+	assert.NoError(t, err)
+	names := func(middlewares []contracts.MagicalFunc) []string {
+		var results []string
+		for _, middleware := range middlewares {
+			results = append(results, routing.MiddlewareName(middleware))
+		}
+		return results
+	}
+
+	middlewares := router.RouteMiddlewares(route)
+	assert.Equal(t, []string{"session", "auth"}, names(middlewares))
+	middlewares[0] = middlewares[1]
+	assert.Equal(t, []string{"session", "auth"}, names(router.RouteMiddlewares(route)))
+	assert.Equal(t, []string{"session", "auth"}, names(routing.NewRoutePipeline(nil, router, route).Middlewares()))
+}
+
+func TestHttpRouterWithoutMiddlewareFunc(t *testing.T) {
+	var calls []string
+	logger := func(next contracts.Pipe) any {
+		calls = append(calls, "logger")
+		return next(nil)
+	}
+	auth := func(next contracts.Pipe) any {
+		calls = append(calls, "auth")
+		return next(nil)
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Use(logger)
+	router.AliasMiddleware("auth", auth)
+	group := router.Group("/app", "auth")
+	group.Get("/profile", func() string { return "profile" })
+	group.Get("/login", func() string { return "login" }, routing.Without(auth, logger))
+	group.Group("/public").(*routing.Group).WithoutMiddleware(auth).Get("/about", func() string { return "about" })
+	assert.NoError(t, router.Mount())
+
+	for path, expected := range map[string][]string{
+		"/app/profile":      {"logger", "auth"},
+		"/app/login":        nil,
+		"/app/public/about": {"logger"},
+	} {
+		calls = nil
+		route, _, err := router.Route(http.MethodGet, &url.URL{Path: path})
+		assert.NoError(t, err, path)
+		routing.NewRoutePipeline(nil, router, route).Then(nil, route.Handler())
+		assert.Equal(t, expected, calls, path)
+	}
+
+	assert.PanicsWithError(t, routing.ExcludedMiddlewareError.Error(), func() {
+		group.Get("/admin", func() string { return "admin" }, routing.Without(1))
+	})
+	assert.PanicsWithError(t, routing.ExcludedMiddlewareError.Error(), func() {
+		router.Get("/admin", func() string { return "admin" }).(*routing.Route).WithoutMiddleware(struct{}{})
+	})
+}
+
+func TestHttpRouterMiddlewarePriority(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	for _, name := range []string{"session", "auth", "log", "throttle"} {
+		router.AliasMiddleware(name, func(next contracts.Pipe) any { return next(nil) })
+	}
+	router.MiddlewarePriority("session", "auth")
+	router.Use("log", "auth")
+	router.Group("/app", "throttle", "session").Get("/profile", func() string { return "profile" })
+	assert.NoError(t, router.Mount())
+
+	names, err := router.MiddlewareNames(http.MethodGet, "/app/profile")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"log", "session", "throttle", "auth"}, names)
+	routingtest.AssertMiddlewareOrder(t, router, http.MethodGet, "/app/profile", "session", "auth")
+	assert.False(t, routingtest.AssertMiddlewareOrder(&fakeT{}, router, http.MethodGet, "/app/profile", "auth", "session"))
+}
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+type photoController struct{}
+
+func (photoController) Index() string   { return "index" }
+func (photoController) Create() string  { return "create" }
+func (photoController) Store() string   { return "store" }
+func (photoController) Show() string    { return "show" }
+func (photoController) Edit() string    { return "edit" }
+func (photoController) Update() string  { return "update" }
+func (photoController) Destroy() string { return "destroy" }
+
+func TestHttpRouterResource(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	assert.Len(t, router.Resource("photos", photoController{}), 7)
+	assert.Len(t, router.ApiResource("photos.comments", photoController{}, routing.Shallow()), 5)
+	assert.Len(t, router.Resource("profile", photoController{}, routing.Singleton()), 3)
+	router.Group("/admin").(*routing.Group).Resource("categories", photoController{}, routing.Only("index", "show"))
+	assert.NoError(t, router.Mount())
+
+	cases := []struct {
+		method, path, name string
+		params             contracts.RouteParams
+	}{
+		{http.MethodGet, "/photos", "photos.index", nil},
+		{http.MethodGet, "/photos/create", "photos.create", nil},
+		{http.MethodPatch, "/photos/1", "photos.update", contracts.RouteParams{"photo": "1"}},
+		{http.MethodGet, "/photos/1/edit", "photos.edit", contracts.RouteParams{"photo": "1"}},
+		{http.MethodPost, "/photos/1/comments", "photos.comments.store", contracts.RouteParams{"photo": "1"}},
+		{http.MethodDelete, "/comments/2", "comments.destroy", contracts.RouteParams{"comment": "2"}},
+		{http.MethodGet, "/profile/edit", "profile.edit", nil},
+		{http.MethodGet, "/admin/categories/3", "categories.show", contracts.RouteParams{"category": "3"}},
+	}
+	for _, item := range cases {
+		route, params, err := router.Route(item.method, &url.URL{Path: item.path})
+		if assert.NoError(t, err, item.path) {
+			assert.Equal(t, item.name, route.GetName())
+			for key, value := range item.params {
+				assert.Equal(t, value, params[key])
+			}
+		}
+	}
+
+	_, _, err := router.Route(http.MethodGet, &url.URL{Path: "/photos/1/comments/2/edit"})
+	assert.Error(t, err)
+	_, _, err = router.Route(http.MethodDelete, &url.URL{Path: "/admin/categories/3"})
+	assert.Error(t, err)
+}
+
+type userController struct {
+	greeting string
+}
+
+func (controller *userController) Index() string { return controller.greeting + " users" }
+func (controller *userController) Show(params contracts.RouteParams) string {
+	return controller.greeting + " user " + params["id"]
+}
+
+type reportController struct{}
+
+func (reportController) Routes() []routing.ControllerRoute {
+	return []routing.ControllerRoute{
+		{Method: http.MethodGet, Path: "/daily", Action: "Daily", Name: "reports.today"},
+	}
+}
+
+func (reportController) Daily() string { return "daily" }
+
+func TestHttpRouterController(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Controller("/users", &userController{greeting: "hello"})
+	router.Controller("/reports", reportController{})
+	assert.NoError(t, router.Mount())
+
+	route, params, err := router.Route(http.MethodGet, &url.URL{Path: "/users/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "users.show", route.GetName())
+	assert.Equal(t, "hello user 1", routing.NewPipeline(nil).Then(nil, route.Handler(), params))
+
+	route, _, err = router.Route(http.MethodGet, &url.URL{Path: "/users"})
+	assert.NoError(t, err)
+	assert.Equal(t, "users.index", route.GetName())
+
+	route, _, err = router.Route(http.MethodGet, &url.URL{Path: "/reports/daily"})
+	assert.NoError(t, err)
+	assert.Equal(t, "reports.today", route.GetName())
+	assert.Equal(t, "daily", routing.NewPipeline(nil).Then(nil, route.Handler()))
+}
+
+type visitCounter struct {
+	total int
+}
+
+type visitController struct {
+	visits  int
+	counter *visitCounter
+}
+
+func (controller *visitController) Show(params contracts.RouteParams) string {
+	controller.visits++
+	controller.counter.total++
+	return fmt.Sprintf("%s: %d/%d", params["id"], controller.visits, controller.counter.total)
+}
+
+func TestHttpRouterControllerFromContainer(t *testing.T) {
+	var created int
+	counter := &visitCounter{}
+	app := container.New()
+	// 容器按参数类型的键解析控制器
+	app.Bind(reflect.TypeOf(visitController{}).PkgPath()+".visitController", func() *visitController {
+		created++
+		return &visitController{counter: counter}
+	})
+
+	// 注册时的控制器没有依赖，每次请求都应该使用容器解析出来的新实例
+	router := routing.NewHttpRouter(app).(*routing.HttpRouter)
+	router.Controller("/visits", &visitController{})
+	assert.NoError(t, router.Mount())
+
+	handler := routing.NewHandler(app, router)
+	for i := 1; i <= 3; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/visits/7", nil))
+		assert.Equal(t, fmt.Sprintf("7: 1/%d", i), recorder.Body.String())
+	}
+	assert.Equal(t, 3, created)
+}
+
+func TestHttpRouterFallback(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/app/login", func() string { return "login" })
+	router.Fallback(func() string { return "global" }).Name("fallback")
+	router.Group("/app").(*routing.Group).Fallback(func() string { return "spa" })
+	api := router.Group("/api").Host("api.example.com").(*routing.Group)
+	api.Get("/users", func() string { return "users" })
+	api.Fallback(func() string { return "api" })
+	assert.NoError(t, router.Mount())
+
+	cases := map[string]string{
+		"http://example.com/anything":          "global",
+		"http://example.com/app/settings/deep": "spa",
+		"http://api.example.com/api/missing":   "api",
+		"http://example.com/api/missing":       "global",
+	}
+	for rawUrl, expected := range cases {
+		u, _ := url.Parse(rawUrl)
+		route, _, err := router.Route(http.MethodGet, u)
+		if assert.NoError(t, err, rawUrl) {
+			assert.Equal(t, expected, routing.NewPipeline(nil).Then(nil, route.Handler()), rawUrl)
+		}
+	}
+
+	_, _, err := router.Route(http.MethodPost, &url.URL{Path: "/app/login"})
+	assert.ErrorIs(t, err, routing.MethodNotAllowErr)
+}
+
+func TestHttpRouterPrefixBeforeMethodNotAllowed(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/admin/users", func() string { return "users" })
+	router.Get("/reports", func() string { return "reports" })
+	router.Prefix("/admin", func() string { return "admin" })
+	router.Fallback(func() string { return "fallback" })
+	assert.NoError(t, router.Mount())
+
+	// 前缀路由与普通路由一起参与请求方法的匹配，优先于 405
+	route, params, err := router.Route(http.MethodPost, &url.URL{Path: "/admin/users"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/admin", route.GetPath())
+	assert.Equal(t, "/users", params[routing.RemainderParam])
+	assert.Len(t, router.AllowedMethods(&url.URL{Path: "/admin/users"}), 9)
+
+	// fallback 排在 405 之后
+	_, _, err = router.Route(http.MethodPost, &url.URL{Path: "/reports"})
+	assert.ErrorIs(t, err, routing.MethodNotAllowErr)
+	route, _, err = router.Route(http.MethodPost, &url.URL{Path: "/missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", routing.NewPipeline(nil).Then(nil, route.Handler()))
+}
+
+func TestHttpRouterRouteMeta(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	group := router.Group("/billing").(*routing.Group)
+	group.With("team", "payments")
+	group.With("tier", "standard")
+	group.Get("/invoices", func() string { return "invoices" })
+	invoices := group.Routes()[0].(*routing.Route)
+	invoices.With("tier", "premium")
+	invoices.With("scopes", []string{"invoices:read"})
+	assert.NoError(t, router.Mount())
+
+	route, _, err := router.Route(http.MethodGet, &url.URL{Path: "/billing/invoices"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"team":   "payments",
+		"tier":   "premium",
+		"scopes": []string{"invoices:read"},
+	}, routing.RouteMeta(route))
+
+	scopes, ok := routing.MetaValue[[]string](route, "scopes")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"invoices:read"}, scopes)
+	_, ok = routing.MetaValue[int](route, "team")
+	assert.False(t, ok)
+}
+
+func TestHttpRouterList(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	router.Get("/users/{id:[0-9]+}", func() string { return "user" }, "auth").Name("users.show")
+	router.Post("/users", func() string { return "store" }).Name("users.store")
+	router.Group("/api").Host("api.example.com").Get("/status", func() string { return "ok" })
+	router.Fallback(func() string { return "fallback" })
+	assert.NoError(t, router.Mount())
+
+	assert.Len(t, router.List(routing.RouteFilter{}), 4)
+	assert.Len(t, router.List(routing.RouteFilter{Host: "api.example.com"}), 1)
+	assert.Len(t, router.List(routing.RouteFilter{PathPrefix: "/users"}), 2)
+
+	routes := router.List(routing.RouteFilter{Method: "get", Name: "users.*"})
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, "/users/{id:[0-9]+}", routes[0].Path)
+		assert.Equal(t, []string{"auth"}, routes[0].Middlewares)
+		assert.Contains(t, routes[0].Handler, "TestHttpRouterList")
+	}
+
+	// 处理器名称单独记录，处理器仍然是容器创建的 contracts.MagicalFunc
+	route, _, err := router.Route(http.MethodPost, &url.URL{Path: "/users"})
+	assert.NoError(t, err)
+	assert.IsType(t, container.NewMagicalFunc(func() {}), route.Handler())
+
+	var table, jsonOutput strings.Builder
+	assert.NoError(t, routing.RenderRouteTable(&table, router.List(routing.RouteFilter{})))
+	assert.Contains(t, table.String(), "users.show")
+	assert.Contains(t, table.String(), "/*")
+	assert.NoError(t, routing.RenderRouteJson(&jsonOutput, routes))
+	assert.Contains(t, jsonOutput.String(), `"name": "users.show"`)
+}
+
+func TestHttpRouterListMountedRouter(t *testing.T) {
+	sub := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	sub.AliasMiddleware("auth", func(next contracts.Pipe) any { return next(nil) })
+	invoices := sub.Group("/invoices", "auth")
+	invoices.Get("/{id}", func() string { return "invoice" }, "web")
+	invoices.Get("/{id}/pdf", func() string { return "pdf" }, routing.Without("auth", "session"))
+	sub.Get("/summary", func() string { return "summary" }, "auth").Name("summary")
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.AliasMiddleware("session", func(next contracts.Pipe) any { return next(nil) })
+	router.AliasMiddleware("csrf", func(next contracts.Pipe) any { return next(nil) })
+	router.MiddlewareGroup("web", "csrf")
+	router.Use("session")
+	router.MountRouter("/billing", sub)
+	assert.NoError(t, router.Mount())
+
+	routes := router.List(routing.RouteFilter{PathPrefix: "/billing"})
+	if assert.Len(t, routes, 3) {
+		assert.Equal(t, "/billing/invoices/{id}", routes[0].Path)
+		assert.Equal(t, []string{"session", "auth", "csrf"}, routes[0].Middlewares)
+		assert.Equal(t, "/billing/invoices/{id}/pdf", routes[1].Path)
+		assert.Equal(t, []string{}, routes[1].Middlewares)
+		assert.Equal(t, "billing.summary", routes[2].Name)
+		assert.Equal(t, []string{"session", "auth"}, routes[2].Middlewares)
+	}
+}
+
+type createUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type userResource struct {
+	Id      int       `json:"id"`
+	Name    string    `json:"name"`
+	Friends []*Friend `json:"friends"`
+}
+
+type Friend struct {
+	Name string `json:"name"`
+}
+
+func TestHttpRouterOpenAPI(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Post("/users", func(request createUserRequest) userResource { return userResource{} }).Name("users.store")
+	show := router.Get("/users/{id:[0-9]+}/{tab?}", func() *userResource { return nil }).Name("users.show").(*routing.Route)
+	show.With("summary", "Show a user")
+	router.Fallback(func() string { return "fallback" })
+	assert.NoError(t, router.Mount())
+
+	document, err := router.OpenAPI(routing.OpenAPIInfo{Title: "api", Version: "1.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	assert.Len(t, document.Paths, 3)
+
+	store := document.Paths["/users"]["post"]
+	assert.Equal(t, "users.store", store.OperationId)
+	assert.Equal(t, "#/components/schemas/createUserRequest", store.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, []string{"name"}, document.Components.Schemas["createUserRequest"].Required)
+	assert.Equal(t, "array", document.Components.Schemas["userResource"].Properties["friends"].Type)
+	assert.Contains(t, document.Components.Schemas, "Friend")
+
+	showOperation := document.Paths["/users/{id}/{tab}"]["get"]
+	assert.Equal(t, "users.show", showOperation.OperationId)
+	assert.Equal(t, "Show a user", showOperation.Summary)
+	assert.Equal(t, "[0-9]+", showOperation.Parameters[0].Schema.Pattern)
+	assert.Len(t, showOperation.Parameters, 2)
+	assert.Equal(t, "users.show.without_tab", document.Paths["/users/{id}"]["get"].OperationId)
+}
+
+func TestHttpRouterOpenAPISchemaNames(t *testing.T) {
+	type Friend struct {
+		Nickname string `json:"nickname"`
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/friends", func() Friend { return Friend{} })
+	router.Get("/users", func() userResource { return userResource{} })
+	assert.NoError(t, router.Mount())
+
+	document, err := router.OpenAPI(routing.OpenAPIInfo{Title: "api", Version: "1.0.0"})
+	assert.NoError(t, err)
+	assert.Len(t, document.Components.Schemas, 3)
+	assert.Equal(t, "#/components/schemas/Friend", document.Paths["/friends"]["get"].Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Contains(t, document.Components.Schemas["Friend"].Properties, "nickname")
+	assert.Contains(t, document.Components.Schemas["github.com.goal-web.routing_test.Friend"].Properties, "name")
+}
+
+func TestHttpRouterOpenAPIPathConflict(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/users/{id?}", func() string { return "show" })
+	router.Get("/users", func() string { return "index" })
+	assert.NoError(t, router.Mount())
+
+	_, err := router.OpenAPI(routing.OpenAPIInfo{Title: "api", Version: "1.0.0"})
+	assert.ErrorIs(t, err, routing.OpenAPIPathConflictErr)
+	assert.ErrorIs(t, router.WriteOpenAPI(io.Discard, routing.OpenAPIInfo{}), routing.OpenAPIPathConflictErr)
+}
+
+const usersOpenAPI = `{
+  "openapi": "3.1.0",
+  "info": {"title": "users", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "get": {"operationId": "users.index", "responses": {"200": {"description": "OK"}}}
+    },
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "pattern": "[0-9]+"}}],
+      "get": {"operationId": "users.show", "summary": "Show a user", "responses": {"200": {"description": "OK"}}},
+      "delete": {"operationId": "users.destroy", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func TestHttpRouterRegisterOpenAPI(t *testing.T) {
+	document, err := routing.LoadOpenAPI(strings.NewReader(usersOpenAPI))
+	assert.NoError(t, err)
+
+	handlers := routing.HandlerRegistry{
+		"users.index":   func() string { return "index" },
+		"users.show":    func() string { return "show" },
+		"users.destroy": func() string { return "destroy" },
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.RegisterOpenAPI(document, handlers)
+	assert.NoError(t, router.Mount())
+
+	route, _, err := router.Route(http.MethodGet, &url.URL{Path: "/users/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "users.show", route.GetName())
+	assert.Equal(t, "/users/{id:[0-9]+}", route.GetPath())
+	_, _, err = router.Route(http.MethodGet, &url.URL{Path: "/users/abc"})
+	assert.Error(t, err)
+
+	delete(handlers, "users.destroy")
+	router = routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.RegisterOpenAPI(document, handlers)
+	router.Post("/users", func() string { return "store" })
+	err = router.Mount()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "operations without handler [[DELETE] /users/{id} (users.destroy)]")
+	assert.Contains(t, err.Error(), "routes not described by the OpenAPI document [[POST] /users]")
+}
+
+func TestGenerateOpenAPIStubs(t *testing.T) {
+	document, err := routing.LoadOpenAPI(strings.NewReader(usersOpenAPI))
+	assert.NoError(t, err)
+
+	var source strings.Builder
+	assert.NoError(t, routing.GenerateOpenAPIStubs(&source, document, "handlers"))
+	assert.Contains(t, source.String(), `"users.show":    UsersShow,`)
+	assert.Contains(t, source.String(), "// UsersShow [GET] /users/{id} Show a user\nfunc UsersShow() any {\n\treturn fmt.Errorf(\"%w: %s\", routing.NotImplementedErr, \"users.show\")\n}")
+	assert.NotContains(t, source.String(), "panic(")
+}
+
+func TestLoadOpenAPIYaml(t *testing.T) {
+	document, err := routing.LoadOpenAPI(strings.NewReader(`openapi: 3.1.0
+info:
+  title: users
+  version: 1.0.0
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string, pattern: "[0-9]+"}
+    get:
+      operationId: users.show
+      responses:
+        200:
+          description: OK
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "users", document.Info.Title)
+	show := document.Paths["/users/{id}"]["get"]
+	assert.Equal(t, "users.show", show.OperationId)
+	assert.Equal(t, "[0-9]+", show.Parameters[0].Schema.Pattern)
+	assert.Equal(t, "OK", show.Responses["200"].Description)
+}
+
+func TestLoadOpenAPIDuplicateOperationId(t *testing.T) {
+	_, err := routing.LoadOpenAPI(strings.NewReader(`{
+  "paths": {
+    "/users": {"get": {"operationId": "users.index"}},
+    "/members": {"get": {"operationId": "users.index"}}
+  }
+}`))
+	assert.ErrorContains(t, err, "duplicate operationId")
+
+	document := &routing.OpenAPIDocument{Paths: map[string]map[string]*routing.OpenAPIOperation{
+		"/users":   {"get": {OperationId: "users.index"}},
+		"/members": {"get": {OperationId: "users-index"}},
+	}}
+	assert.ErrorContains(t, routing.GenerateOpenAPIStubs(io.Discard, document, "handlers"), "both generate UsersIndex")
+}
+
+func TestParseRouteConfig(t *testing.T) {
+	yamlConfig := `routes:
+  - method: get
+    path: /users
+    name: users.index
+    handler: users.index
+    middlewares: [auth]
+
+  - methods: [PUT, PATCH]
+    path: /users/{id}
+    handler: users.update
+    disabled: true
+`
+	jsonConfig := `{
+  "version": 1,
+  "routes": [
+    {"method": "GET", "path": "/users", "name": "users.index", "handler": "users.index", "middlewares": ["auth"]},
+    {
+      "methods": ["PUT", "PATCH"], "path": "/users/{id}", "handler": "users.update", "disabled": true
+    }
+  ]
+}`
+	tomlConfig := `[[routes]]
+method = "GET"
+path = "/users"
+name = "users.index"
+handler = "users.index"
+middlewares = ["auth"]
+
+[[routes]]
+methods = ["PUT", "PATCH"]
+path = "/users/{id}"
+handler = "users.update"
+disabled = true
+`
+	for filename, content := range map[string]string{
+		"routes.yaml": yamlConfig,
+		"routes.json": jsonConfig,
+		"routes.toml": tomlConfig,
+	} {
+		routes, err := routing.ParseRouteConfig([]byte(content), filename)
+		assert.NoError(t, err, filename)
+		if !assert.Len(t, routes, 2, filename) {
+			continue
+		}
+		assert.Equal(t, "users.index", routes[0].Name, filename)
+		assert.Equal(t, []string{"auth"}, routes[0].Middlewares, filename)
+		assert.Equal(t, []string{"PUT", "PATCH"}, routes[1].Methods, filename)
+		assert.True(t, routes[1].Disabled, filename)
+		assert.Equal(t, filename, routes[1].File)
+	}
+
+	routes, _ := routing.ParseRouteConfig([]byte(yamlConfig), "routes.yaml")
+	assert.Equal(t, []int{2, 8}, []int{routes[0].Line, routes[1].Line})
+	routes, _ = routing.ParseRouteConfig([]byte(jsonConfig), "routes.json")
+	assert.Equal(t, []int{4, 5}, []int{routes[0].Line, routes[1].Line})
+	routes, _ = routing.ParseRouteConfig([]byte(tomlConfig), "routes.toml")
+	assert.Equal(t, []int{1, 8}, []int{routes[0].Line, routes[1].Line})
+
+	_, err := routing.ParseRouteConfig([]byte(yamlConfig), "routes.ini")
+	assert.Error(t, err)
+}
+
+func TestHttpRouterLoadRoutes(t *testing.T) {
+	filename := t.TempDir() + "/routes.yaml"
+	assert.NoError(t, os.WriteFile(filename, []byte(`routes:
+  - method: GET
+    path: /users/{id}
+    name: users.show
+    host: api.example.com
+    handler: users.show
+  - method: GET
+    path: /legacy
+    handler: legacy
+    disabled: true
+`), 0o644))
+
+	handlers := routing.HandlerRegistry{
+		"users.show": func() string { return "show" },
+	}
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	assert.NoError(t, router.LoadRoutes(filename, handlers))
+	assert.NoError(t, router.Mount())
+
+	route, _, err := router.Route(http.MethodGet, &url.URL{Host: "api.example.com", Path: "/users/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "users.show", route.GetName())
+	assert.Equal(t, "api.example.com", route.GetHost())
+	_, _, err = router.Route(http.MethodGet, &url.URL{Path: "/legacy"})
+	assert.Error(t, err)
+
+	group := routing.NewGroup("/admin").(*routing.Group)
+	assert.NoError(t, group.LoadRoutes(filename, handlers))
+	assert.Equal(t, "/admin/users/{id}", group.Routes()[0].GetPath())
+
+	badFilename := t.TempDir() + "/routes.yaml"
+	assert.NoError(t, os.WriteFile(badFilename, []byte(`routes:
+  - method: GET
+    path: /users
+    handler: users.index
+  - method: FETCH
+    path: users
+    handler: users.show
+`), 0o644))
+	router = routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	err = router.LoadRoutes(badFilename, handlers)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), badFilename+`:2: unknown handler "users.index"`)
+	assert.Contains(t, err.Error(), badFilename+`:5: unknown method "FETCH"`)
+	assert.Contains(t, err.Error(), badFilename+`:5: path "users" must start with /`)
+	assert.Empty(t, router.List(routing.RouteFilter{}))
+}
+
+func TestRouteConfigValidation(t *testing.T) {
+	app := container.New()
+	app.Instance("users.count", 42)
+	app.Instance("users.index", func() string { return "index" })
+
+	router := routing.NewHttpRouter(app).(*routing.HttpRouter)
+	router.AliasMiddleware("throttle", func(next contracts.Pipe) any { return next(nil) })
+	router.MiddlewareGroup("web", "throttle")
+	err := router.RegisterRouteConfigs([]routing.RouteConfig{
+		{Method: "GET", Path: "/users/count", Handler: "users.count", File: "routes.yaml", Line: 2},
+		{Method: "GET", Path: "/users", Handler: "users.index", Middlewares: []string{"throttle:60,1", "web", "auth"}, File: "routes.yaml", Line: 5},
+	}, nil)
+	var configErr *routing.RouteConfigError
+	assert.ErrorAs(t, err, &configErr)
+	assert.Equal(t, []string{
+		`routes.yaml:2: handler "users.count" is not a function`,
+		`routes.yaml:5: unknown middleware "auth"`,
+	}, configErr.Errors)
+
+	group := router.Group("/admin").Group("/v1").(*routing.Group)
+	err = group.RegisterRouteConfigs([]routing.RouteConfig{
+		{Method: "GET", Path: "/users", Handler: "users.index", Middlewares: []string{"session"}, File: "admin.yaml", Line: 3},
+	}, nil)
+	assert.EqualError(t, err, "invalid route config:\nadmin.yaml:3: unknown middleware \"session\"")
+
+	// Mount 时的冲突标明路由配置所在的文件和行号
+	router.Get("/users", func() string { return "users" })
+	assert.NoError(t, router.RegisterRouteConfigs([]routing.RouteConfig{
+		{Method: "GET", Path: "/users", Handler: "users.index", Middlewares: []string{"web"}, File: "routes.yaml", Line: 8},
+	}, nil))
+	err = router.Mount()
+	assert.ErrorContains(t, err, "routes.yaml:8")
+}
+
+func TestRouteConfigHandlersFromContainer(t *testing.T) {
+	app := container.New()
+	app.Instance("users.index", func() string { return "index" })
+	routes := []routing.RouteConfig{{Method: "GET", Path: "/users", Name: "users.index", Handler: "users.index"}}
+
+	router := routing.NewHttpRouter(app).(*routing.HttpRouter)
+	assert.NoError(t, router.RegisterRouteConfigs(routes, nil))
+
+	group := router.Group("/admin").Group("/v1").(*routing.Group)
+	assert.NoError(t, group.RegisterRouteConfigs(routes, nil))
+	assert.NoError(t, router.Mount())
+
+	for _, path := range []string{"/users", "/admin/v1/users"} {
+		route, _, err := router.Route(http.MethodGet, &url.URL{Path: path})
+		assert.NoError(t, err, path)
+		assert.Equal(t, "users.index", route.GetName(), path)
+	}
+
+	err := routing.NewGroup("/admin").(*routing.Group).RegisterRouteConfigs(routes, nil)
+	assert.ErrorContains(t, err, `unknown handler "users.index"`)
+}
+
+func TestHttpRouterCache(t *testing.T) {
+	register := func(router *routing.HttpRouter) {
+		router.Get("/users/{id:[0-9]+}", func() string { return "show" }).Name("users.show")
+		router.Get("/users/{id:[0-9]+}/edit", func() string { return "edit" })
+		router.Get("/posts/{slug?}", func() string { return "posts" })
+		router.Get("/status", func() string { return "status" }).Host("{tenant}.example.com")
+		router.Prefix("/admin", func() string { return "admin" })
+		router.Fallback(func() string { return "fallback" })
+	}
+
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	var cache strings.Builder
+	assert.ErrorIs(t, router.Cache(&cache), routing.RouterNotMountedErr)
+	register(router)
+	assert.NoError(t, router.Mount())
+	assert.NoError(t, router.Cache(&cache))
+
+	cached := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	register(cached)
+	assert.NoError(t, cached.LoadCache(strings.NewReader(cache.String())))
+
+	for _, u := range []*url.URL{
+		{Path: "/users/1"},
+		{Path: "/users/1/edit"},
+		{Path: "/posts"},
+		{Path: "/posts/hello"},
+		{Host: "acme.example.com", Path: "/status"},
+		{Path: "/admin/deep"},
+		{Path: "/missing/page"},
+	} {
+		expected, expectedParams, expectedErr := router.Route(http.MethodGet, u)
+		route, params, err := cached.Route(http.MethodGet, u)
+		assert.Equal(t, expectedErr, err, u.String())
+		assert.Equal(t, expected.GetPath(), route.GetPath(), u.String())
+		assert.Equal(t, expectedParams, params, u.String())
+	}
+	path, err := cached.URL("users.show", map[string]any{"id": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/1", path)
+
+	changed := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	register(changed)
+	changed.Get("/new", func() string { return "new" })
+	assert.ErrorIs(t, changed.LoadCache(strings.NewReader(cache.String())), routing.RouteCacheStaleErr)
+	assert.NoError(t, changed.Mount())
+
+	assert.Contains(t, cache.String(), `"rule":"[0-9]+"`)
+	for _, rule := range []string{`"rule":"[a-z]+"`, `"rule":"[0-9"`} {
+		tampered := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+		register(tampered)
+		content := strings.Replace(cache.String(), `"rule":"[0-9]+"`, rule, 1)
+		assert.ErrorIs(t, tampered.LoadCache(strings.NewReader(content)), routing.RouteCacheStaleErr, rule)
+		assert.NoError(t, tampered.Mount(), rule)
+	}
+}
+
+func TestGenerateURLHelpers(t *testing.T) {
+	router := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	router.Get("/users/{id:[0-9]+}", func() string { return "show" }).Name("users.show")
+	router.Get("/archives/{year:[0-9]+?}/{type?}", func() string { return "archives" }).Name("archives")
+	router.Get("/", func() string { return "home" }).Name("home")
+	router.Get("/about", func() string { return "about" })
+
+	var source strings.Builder
+	assert.NoError(t, routing.GenerateURLHelpers(&source, "routes", router.NamedRoutes()))
+	assert.Contains(t, source.String(), "// UsersShow users.show /users/{id:[0-9]+}\nfunc UsersShow(id uint) string {\n\treturn \"/users/\" + strconv.FormatUint(uint64(id), 10)\n}")
+	assert.Contains(t, source.String(), "func Archives(year *uint, typeParam string) string {")
+	assert.Contains(t, source.String(), "yearValue = strconv.FormatUint(uint64(*year), 10)")
+	assert.Contains(t, source.String(), "return cleanRoutePath(\"/archives/\"+yearValue+\"/\"+url.PathEscape(typeParam), false)")
+	assert.Contains(t, source.String(), "func Home() string {\n\treturn \"/\"\n}")
+	assert.NotContains(t, source.String(), "About")
+
+	err := routing.GenerateURLHelpers(&source, "routes", map[string]string{"users.show": "/users/{id}", "users_show": "/u/{id}"})
+	assert.Error(t, err)
+
+	source.Reset()
+	assert.NoError(t, routing.GenerateURLHelpers(&source, "routes", map[string]string{
+		"posts.show": "/users/{user_id}/{userId}/posts/{id:[0-9]+?}/{id_value?}",
+	}))
+	assert.Contains(t, source.String(), "func PostsShow(userId string, userId2 string, id *uint, idValue string) string {")
+	assert.Contains(t, source.String(), "var idValue2 string")
+}
+
+// countingRouter 记录 Find 调用次数，用于确认 HttpRouter 使用了自定义的路由器
+type countingRouter[T any] struct {
+	contracts.Router[T]
+	finds *int
+}
+
+func (router countingRouter[T]) Find(path string) (T, contracts.RouteParams, error) {
+	*router.finds++
+	return router.Router.Find(path)
+}
+
+func TestHttpRouterFactories(t *testing.T) {
+	var pathFinds, hostFinds, pathRouters int
+	router := routing.NewHttpRouter(nil,
+		routing.WithRouterFactory(func() contracts.Router[contracts.Route] {
+			pathRouters++
+			return countingRouter[contracts.Route]{Router: routing.NewRouter[contracts.Route](), finds: &pathFinds}
+		}),
+		routing.WithHostRouterFactory(func() contracts.Router[string] {
+			return countingRouter[string]{Router: routing.NewRouter[string](), finds: &hostFinds}
+		}),
+	).(*routing.HttpRouter)
+	router.Get("/users/{id}", func() string { return "show" })
+	router.Get("/status", func() string { return "status" }).Host("{tenant}.example.com")
+	router.Fallback(func() string { return "fallback" })
+	assert.NoError(t, router.Mount())
+	assert.Equal(t, 2, pathRouters, "fallback routes do not use the path router factory")
+
+	_, params, err := router.Route(http.MethodGet, &url.URL{Path: "/users/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", params["id"])
+	assert.Greater(t, pathFinds, 0)
+
+	_, params, err = router.Route(http.MethodGet, &url.URL{Host: "acme.example.com", Path: "/status"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", params["tenant"])
+	assert.Greater(t, hostFinds, 0)
+
+	// 自定义的路由器没有实现 PrefixRouter，前缀路由使用内置的 Router
+	route, params, err := router.Route(http.MethodGet, &url.URL{Path: "/missing/page"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/", route.GetPath())
+	assert.Equal(t, "/missing/page", params[routing.RemainderParam])
+
+	var cache strings.Builder
+	assert.ErrorIs(t, router.Cache(&cache), routing.RouterNotCacheableError)
+
+	var prefixRouters int
+	router = routing.NewHttpRouter(nil, routing.WithPrefixRouterFactory(func() routing.PrefixRouter[contracts.Route] {
+		prefixRouters++
+		return routing.NewRouter[contracts.Route]().(routing.PrefixRouter[contracts.Route])
+	})).(*routing.HttpRouter)
+	router.Prefix("/admin", func() string { return "admin" })
+	assert.NoError(t, router.Mount())
+	assert.Equal(t, 9, prefixRouters)
+	route, _, err = router.Route(http.MethodPost, &url.URL{Path: "/admin/users"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/admin", route.GetPath())
+}
+
+func TestHttpRouterPathOptions(t *testing.T) {
+	resolve := func(router *routing.HttpRouter, rawURL string) string {
+		u := &url.URL{Path: rawURL}
+		if index := strings.Index(rawURL, "?"); index > -1 {
+			u.Path, u.RawQuery = rawURL[:index], rawURL[index+1:]
+		}
+		route, params, err := router.Route(http.MethodGet, u)
+		var redirect *routing.RedirectError
+		switch {
+		case errors.As(err, &redirect):
+			return fmt.Sprintf("%d %s", redirect.Status, redirect.Location)
+		case err != nil:
+			return "404"
+		case params["page"] != "":
+			return route.GetPath() + " page=" + params["page"]
+		}
+		return route.GetPath()
+	}
+
+	cases := []struct {
+		name     string
+		options  []routing.HttpRouterOption
+		expected map[string]string
+	}{
+		{"default", nil, map[string]string{
+			"/users": "/users", "/users/": "/users", "/posts": "/posts", "/posts/": "/posts",
+			"/docs/Intro": "/docs/{page} page=Intro", "/DOCS/Intro": "404", "//users": "404", "/a/../users": "404",
+		}},
+		{"strict", []routing.HttpRouterOption{routing.WithStrictSlash()}, map[string]string{
+			"/users": "/users", "/users/": "404", "/posts": "404", "/posts/": "/posts/",
+		}},
+		{"case insensitive", []routing.HttpRouterOption{routing.WithCaseInsensitive()}, map[string]string{
+			"/USERS": "/users", "/Users/": "/users", "/DOCS/Intro": "/docs/{page} page=Intro", "/Docs/intro": "/docs/{page} page=intro",
+			"/PRODUCTS/ABC": "/products/{code:[A-Z]+}", "/products/abc": "404",
+		}},
+		{"clean", []routing.HttpRouterOption{routing.WithCleanPath()}, map[string]string{
+			"//users": "/users", "/a/../users": "/users", "/./users/": "/users", "/../users": "/users",
+		}},
+		{"redirect", []routing.HttpRouterOption{routing.WithRedirectTrailingSlash()}, map[string]string{
+			"/users": "/users", "/users/": "301 /users", "/users/?page=2": "301 /users?page=2", "/posts/": "301 /posts",
+		}},
+		{"strict redirect", []routing.HttpRouterOption{routing.WithStrictSlash(), routing.WithRedirectTrailingSlash()}, map[string]string{
+			"/users/": "301 /users", "/posts": "301 /posts/", "/posts/": "/posts/",
+		}},
+		{"clean redirect", []routing.HttpRouterOption{routing.WithCleanPath(), routing.WithRedirectTrailingSlash()}, map[string]string{
+			"//users//": "301 /users", "/a/../users": "/users",
+		}},
+		{"all", []routing.HttpRouterOption{routing.WithStrictSlash(), routing.WithCaseInsensitive(), routing.WithCleanPath(), routing.WithRedirectTrailingSlash()}, map[string]string{
+			"//USERS": "/users", "/Posts": "301 /Posts/", "/x/../Docs/Intro": "/docs/{page} page=Intro", "/docs/Intro/": "301 /docs/Intro",
+		}},
+	}
+
+	for _, item := range cases {
+		router := routing.NewHttpRouter(nil, item.options...).(*routing.HttpRouter)
+		router.Get("/users", func() string { return "users" })
+		router.Get("/posts/", func() string { return "posts" })
+		router.Get("/docs/{page}", func() string { return "docs" })
+		router.Get("/products/{code:[A-Z]+}", func() string { return "products" })
+		assert.NoError(t, router.Mount(), item.name)
+
+		for rawURL, expected := range item.expected {
+			assert.Equal(t, expected, resolve(router, rawURL), "%s: %s", item.name, rawURL)
+		}
+	}
+}
+
+func TestHttpRouterStrictSlashURL(t *testing.T) {
+	router := routing.NewHttpRouter(nil, routing.WithStrictSlash()).(*routing.HttpRouter)
+	router.Get("/posts/", func() string { return "posts" }).Name("posts.index")
+	router.Get("/posts/{id}/", func() string { return "posts" }).Name("posts.show")
+	router.Get("/users/{id?}", func() string { return "users" }).Name("users")
+	assert.NoError(t, router.Mount())
+
+	for name, expected := range map[string]string{"posts.index": "/posts/", "posts.show": "/posts/1/", "users": "/users"} {
+		path, err := router.URL(name, map[string]any{"id": 1})
+		if name == "users" {
+			path, err = router.URL(name, nil)
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, path, name)
+		_, _, err = router.Route(http.MethodGet, &url.URL{Path: path})
+		assert.NoError(t, err, name)
+	}
+
+	var source strings.Builder
+	assert.NoError(t, routing.GenerateURLHelpers(&source, "routes", router.NamedRoutes()))
+	assert.Contains(t, source.String(), `return cleanRoutePath("/posts/"+url.PathEscape(id)+"/", true)`)
+}
+
+func TestHttpRouterPathOptionsRequireBuiltinRouter(t *testing.T) {
+	for _, option := range []routing.HttpRouterOption{routing.WithStrictSlash(), routing.WithCaseInsensitive()} {
+		router := routing.NewHttpRouter(nil, option, routing.WithRouterFactory(func() contracts.Router[contracts.Route] {
+			return NewConformanceMatcher[contracts.Route]()
+		})).(*routing.HttpRouter)
+		router.Get("/users", func() string { return "users" })
+		assert.ErrorContains(t, router.Mount(), "do not support WithStrictSlash or WithCaseInsensitive")
+	}
+
+	router := routing.NewHttpRouter(nil, routing.WithStrictSlash(), routing.WithRouterFactory(routing.NewRouter[contracts.Route])).(*routing.HttpRouter)
+	router.Get("/posts/", func() string { return "posts" })
+	assert.NoError(t, router.Mount())
+	_, _, err := router.Route(http.MethodGet, &url.URL{Path: "/posts"})
+	assert.ErrorIs(t, err, routing.NotFoundErr)
+}
+
+func TestHttpRouterRedirectLeadingSlashes(t *testing.T) {
+	router := routing.NewHttpRouter(nil, routing.WithRedirectTrailingSlash()).(*routing.HttpRouter)
+	router.Get("/{lang?}/{slug}", func() string { return "pages" })
+	assert.NoError(t, router.Mount())
+
+	// //evil.com 能匹配省略 lang 的路由，Location 不能是协议相对的 //evil.com
+	_, _, err := router.Route(http.MethodGet, &url.URL{Path: "//evil.com/"})
+	var redirect *routing.RedirectError
+	assert.ErrorAs(t, err, &redirect)
+	assert.Equal(t, "/evil.com", redirect.Location)
+}
+
+func TestHandlerRedirectTrailingSlash(t *testing.T) {
+	router := routing.NewHttpRouter(nil, routing.WithRedirectTrailingSlash())
+	router.Get("/users", func() string { return "users" })
+	router.Post("/users", func() string { return "store" })
+	assert.NoError(t, router.Mount())
+	handler := routing.NewHandler(nil, router)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/?page=2", nil))
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/users?page=2", recorder.Header().Get("Location"))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, recorder.Code)
+}
+
+func TestHttpRouterCanonicalRedirect(t *testing.T) {
+	router := routing.NewHttpRouter(nil, routing.WithCanonicalRedirect()).(*routing.HttpRouter)
+	router.Get("/users", func() string { return "users" })
+	router.Get("/admin", func() string { return "admin" })
+	router.Get("/docs/{page}", func() string { return "docs" })
+	router.Post("/users", func() string { return "store" })
+	router.Prefix("/files", func() string { return "files" })
+	router.Fallback(func() string { return "fallback" })
+	assert.NoError(t, router.Mount())
+
+	for rawPath, expected := range map[string]string{
+		"/users":                  "",
+		"/users/":                 "/users",
+		"//users":                 "/users",
+		"//users//":               "/users",
+		"/users/../admin":         "/admin",
+		"/./users":                "/users",
+		"/a/b/../../users/":       "/users",
+		"/../users":               "/users",
+		"/docs//getting started/": "/docs/getting%20started",
+	} {
+		u := &url.URL{Path: rawPath, RawQuery: "page=2"}
+		route, _, err := router.Route(http.MethodGet, u)
+		if expected == "" {
+			assert.NoError(t, err, rawPath)
+			assert.Equal(t, rawPath, route.GetPath())
+			continue
+		}
+		var redirect *routing.RedirectError
+		if assert.ErrorAs(t, err, &redirect, rawPath) {
+			assert.Equal(t, expected+"?page=2", redirect.Location, rawPath)
+			assert.Equal(t, http.StatusMovedPermanently, redirect.Status, rawPath)
+		}
+	}
+
+	// 在转义的路径上规范化，%2F 不是路径分隔符，Location 保留转义
+	for rawPath, expected := range map[string]string{
+		"//files/a%2Fb":        "/files/a%2Fb",
+		"/files/x/%2e%2E/a%2F": "/files/a%2F",
+	} {
+		u := &url.URL{RawPath: rawPath}
+		u.Path, _ = url.PathUnescape(rawPath)
+		_, _, err := router.Route(http.MethodGet, u)
+		var redirect *routing.RedirectError
+		if assert.ErrorAs(t, err, &redirect, rawPath) {
+			assert.Equal(t, expected, redirect.Location, rawPath)
+		}
+	}
+
+	// ..%2F 不是 .. 路径段，路径已经是规范的
+	u := &url.URL{Path: "/files/x/..//a", RawPath: "/files/x/..%2F/a"}
+	route, _, err := router.Route(http.MethodGet, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "/files", route.GetPath())
+
+	// 规范路径也不能匹配时不重定向，交给 fallback 处理
+	route, _, err = router.Route(http.MethodGet, &url.URL{Path: "//missing/../nowhere"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/", route.GetPath())
+
+	var redirect *routing.RedirectError
+	_, _, err = router.Route(http.MethodPost, &url.URL{Path: "/x/../users/"})
+	if assert.ErrorAs(t, err, &redirect) {
+		assert.Equal(t, "/users", redirect.Location)
+		assert.Equal(t, http.StatusPermanentRedirect, redirect.Status)
+	}
+
+	recorder := httptest.NewRecorder()
+	routing.NewHandler(nil, router).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/%2e%2e/admin?tab=1", nil))
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/admin?tab=1", recorder.Header().Get("Location"))
+}
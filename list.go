@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteFilter 路由列表的过滤条件，空字段表示不过滤
+type RouteFilter struct {
+	Method     string // 请求方法，不区分大小写
+	PathPrefix string // 路径前缀
+	Name       string // 路由名的通配符，例如 users.*
+	Host       string // 域名
+}
+
+// RouteInfo 路由列表中的一条路由
+type RouteInfo struct {
+	Methods     []string `json:"methods"`
+	Host        string   `json:"host,omitempty"`
+	Path        string   `json:"path"`
+	Prefix      bool     `json:"prefix,omitempty"` // 是否为前缀路由，例如 fallback 和静态文件路由
+	Name        string   `json:"name,omitempty"`
+	Middlewares []string `json:"middlewares"`
+	Handler     string   `json:"handler"`
+}
+
+// List 返回 Mount 注册的路由，包括组内路由、前缀路由和合并进来的子路由器的路由，按路径和请求方法排序。
+// 中间件为最终生效的中间件，Mount 之后调用才能看到解析后的中间件别名
+func (httpRouter *HttpRouter) List(filter RouteFilter) []RouteInfo {
+	// Mount 之后使用已经解析好的路由，子路由器的路由只有挂载时的副本才带有解析和排除后的中间件
+	entries := httpRouter.mountedEntries
+	if httpRouter.names == nil {
+		entries = httpRouter.entries()
+	}
+
+	var routes []RouteInfo
+	for _, entry := range entries {
+		route := entry.route
+		info := RouteInfo{
+			Methods:     append([]string{}, route.Method()...),
+			Host:        route.GetHost(),
+			Path:        route.GetPath(),
+			Prefix:      entry.prefix,
+			Name:        route.GetName(),
+			Middlewares: make([]string, 0),
+			Handler:     FuncIdentity(route.Handler()),
+		}
+		for _, middleware := range httpRouter.RouteMiddlewares(route) {
+			info.Middlewares = append(info.Middlewares, FuncIdentity(middleware))
+		}
+		if filter.matches(info) {
+			routes = append(routes, info)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return strings.Join(routes[i].Methods, "|") < strings.Join(routes[j].Methods, "|")
+	})
+	return routes
+}
+
+func (filter RouteFilter) matches(info RouteInfo) bool {
+	if filter.Method != "" && !containsString(info.Methods, strings.ToUpper(filter.Method)) {
+		return false
+	}
+	if filter.PathPrefix != "" && !strings.HasPrefix(info.Path, filter.PathPrefix) {
+		return false
+	}
+	if filter.Host != "" && info.Host != filter.Host {
+		return false
+	}
+	if filter.Name != "" {
+		if matched, err := path.Match(filter.Name, info.Name); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderRouteTable 把路由列表输出为对齐的文本表格
+func RenderRouteTable(w io.Writer, routes []RouteInfo) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "METHOD\tHOST\tPATH\tNAME\tMIDDLEWARES\tHANDLER")
+	for _, route := range routes {
+		routePath := route.Path
+		if route.Prefix {
+			routePath = strings.TrimSuffix(routePath, "/") + "/*"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			strings.Join(route.Methods, "|"),
+			orDash(route.Host),
+			routePath,
+			orDash(route.Name),
+			orDash(strings.Join(route.Middlewares, ",")),
+			route.Handler,
+		)
+	}
+	return writer.Flush()
+}
+
+// RenderRouteJson 把路由列表输出为 json
+func RenderRouteJson(w io.Writer, routes []RouteInfo) error {
+	if routes == nil {
+		routes = make([]RouteInfo, 0)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(routes)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,408 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	RouteNotCompiledErr = errors.New("route is not compiled into the matcher") // 生成的匹配器只接受生成时已知的路由
+)
+
+// MatcherOptions 生成匹配器的参数
+type MatcherOptions struct {
+	Package string   // 生成文件的包名
+	Name    string   // 生成的类型名，同时生成 New<Name> 构造函数
+	Routes  []string // 编译进匹配器的路由，签名相同的路由只编译第一个，其余的在 Add 时返回 RouteHasExists
+}
+
+// GenerateMatcher 生成实现 contracts.Router[T] 的匹配器源码。
+// 匹配器按照 Router 的路由树展开为静态的前缀比较和分支，简单的字符类约束直接比较字节，其余约束才使用正则
+func GenerateMatcher(w io.Writer, options MatcherOptions) error {
+	if options.Package == "" || options.Name == "" {
+		return errors.New("matcher package and name are required")
+	}
+
+	generator := &matcherGenerator{
+		name:     options.Name,
+		prefix:   string(unicode.ToLower(rune(options.Name[0]))) + options.Name[1:],
+		router:   newRouter[int](),
+		patterns: map[string]int{},
+		rules:    map[string]string{},
+	}
+	slots := map[string]int{}
+	for _, pattern := range options.Routes {
+		slot := len(generator.signatures)
+		signature, err := generator.router.Add(pattern, slot)
+		if err != nil {
+			if existing, exists := slots[signature]; exists {
+				generator.patterns[pattern] = existing
+				continue
+			}
+			return fmt.Errorf("route %s: %w", pattern, err)
+		}
+		slots[signature] = slot
+		generator.patterns[pattern] = slot
+		generator.signatures = append(generator.signatures, signature)
+	}
+
+	formatted, err := format.Source([]byte(generator.generate(options.Package)))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+type matcherGenerator struct {
+	name       string
+	prefix     string // 辅助函数和变量的前缀
+	router     *Router[int]
+	patterns   map[string]int
+	signatures []string
+
+	functions []string
+	rules     map[string]string // 约束到检查函数的映射，空字符串表示总是满足
+	ruleFuncs []string
+	regexp    bool
+}
+
+func (generator *matcherGenerator) generate(packageName string) string {
+	root := generator.walk(generator.router.paramsRoutes)
+
+	var source strings.Builder
+	source.WriteString("// Code generated by routing-matcher. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "package %s\n\nimport (\n\"fmt\"\n", packageName)
+	if generator.regexp {
+		source.WriteString("\"regexp\"\n")
+	}
+	source.WriteString("\"strings\"\n\n\"github.com/goal-web/contracts\"\n\"github.com/goal-web/routing\"\n)\n\n")
+
+	name, prefix, size := generator.name, generator.prefix, len(generator.signatures)
+	fmt.Fprintf(&source, "// %s 编译好的路由匹配器，只能添加生成时已知的路由\n", name)
+	fmt.Fprintf(&source, "type %s[T any] struct {\ndata [%d]T\nadded [%d]bool\n}\n\n", name, size, size)
+	fmt.Fprintf(&source, "var _ contracts.Router[any] = (*%s[any])(nil)\n\n", name)
+	fmt.Fprintf(&source, "func New%s[T any]() contracts.Router[T] {\nreturn &%s[T]{}\n}\n\n", name, name)
+
+	patterns := make([]string, 0, len(generator.patterns))
+	for pattern := range generator.patterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	fmt.Fprintf(&source, "var %sPatterns = map[string]int{\n", prefix)
+	for _, pattern := range patterns {
+		fmt.Fprintf(&source, "%q: %d,\n", pattern, generator.patterns[pattern])
+	}
+	fmt.Fprintf(&source, "}\n\nvar %sSignatures = [%d]string{\n", prefix, size)
+	for _, signature := range generator.signatures {
+		fmt.Fprintf(&source, "%q,\n", signature)
+	}
+	source.WriteString("}\n\n")
+
+	fmt.Fprintf(&source, `func (m *%[1]s[T]) Add(route string, data T) (string, error) {
+slot, exists := %[2]sPatterns[route]
+if !exists {
+return route, fmt.Errorf("%%w: %%s", routing.RouteNotCompiledErr, route)
+}
+if m.added[slot] {
+return %[2]sSignatures[slot], routing.RouteHasExists
+}
+m.data[slot], m.added[slot] = data, true
+return %[2]sSignatures[slot], nil
+}
+
+func (m *%[1]s[T]) IsEmpty() bool {
+for _, added := range m.added {
+if added {
+return false
+}
+}
+return true
+}
+
+`, name, prefix)
+
+	fmt.Fprintf(&source, "func (m *%s[T]) Find(path string) (T, contracts.RouteParams, error) {\n", name)
+	source.WriteString("if len(path) > 1 && path[len(path)-1] == '/' {\npath = path[:len(path)-1]\n}\n")
+	if len(generator.router.paths) > 0 {
+		paths := make([]string, 0, len(generator.router.paths))
+		for path := range generator.router.paths {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		source.WriteString("switch path {\n")
+		for _, path := range paths {
+			slot := generator.router.paths[path]
+			fmt.Fprintf(&source, "case %q:\nif m.added[%d] {\nreturn m.data[%d], nil, nil\n}\n", path, slot, slot)
+		}
+		source.WriteString("}\n")
+	}
+	fmt.Fprintf(&source, `params := make(contracts.RouteParams)
+if result, found := m.%s(path, params); found {
+return result, params, nil
+}
+var zero T
+return zero, params, routing.NotFoundErr
+}
+
+`, root)
+
+	fmt.Fprintf(&source, `// accept 命中路由，路由未添加时继续查找
+func (m *%[1]s[T]) accept(slot int, name, value string, params contracts.RouteParams) (T, bool) {
+if !m.added[slot] {
+var zero T
+return zero, false
+}
+params[name] = value
+return m.data[slot], true
+}
+
+// descend 参数值已通过约束，继续匹配剩余路径
+func (m *%[1]s[T]) descend(name, value, rest, sub string, slot int, children bool, walk func(string, contracts.RouteParams) (T, bool), params contracts.RouteParams) (T, bool) {
+if slot >= 0 && rest == sub {
+if result, found := m.accept(slot, name, value, params); found {
+return result, true
+}
+if !children {
+var zero T
+return zero, false
+}
+}
+params[name] = value
+if result, found := walk(rest, params); found {
+return result, true
+}
+delete(params, name)
+var zero T
+return zero, false
+}
+
+`, name)
+
+	for _, function := range generator.functions {
+		source.WriteString(function)
+	}
+
+	fmt.Fprintf(&source, `func %[1]sCut(path, prefix string) (string, bool) {
+if strings.HasPrefix(path, prefix) {
+return path[len(prefix):], true
+}
+if strings.HasSuffix(prefix, "/") && strings.HasPrefix(path+"/", prefix) {
+return "", true
+}
+return "", false
+}
+
+func %[1]sSegment(value string) (string, string) {
+if index := strings.IndexByte(value, '/'); index > -1 {
+return value[:index], value[index:]
+}
+return value, "/"
+}
+`, prefix)
+	for _, function := range generator.ruleFuncs {
+		source.WriteString(function)
+	}
+	return source.String()
+}
+
+// walk 生成匹配一层路由树的方法，返回方法名
+func (generator *matcherGenerator) walk(tree map[string][]*RouterNode[int]) string {
+	id := len(generator.functions)
+	generator.functions = append(generator.functions, "")
+	method := fmt.Sprintf("walk%d", id)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "func (m *%s[T]) %s(path string, params contracts.RouteParams) (T, bool) {\n", generator.name, method)
+	for _, prefix := range sortedPrefixes(tree) {
+		if len(tree[prefix]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&body, "if value, ok := %sCut(path, %q); ok {\n", generator.prefix, prefix)
+		for _, node := range tree[prefix] {
+			fmt.Fprintf(&body, "if result, found := m.%s(value, params); found {\nreturn result, true\n}\n", generator.node(node))
+		}
+		body.WriteString("}\n")
+	}
+	body.WriteString("var zero T\nreturn zero, false\n}\n\n")
+
+	generator.functions[id] = body.String()
+	return method
+}
+
+// node 生成匹配一个参数节点的方法，返回方法名
+func (generator *matcherGenerator) node(node *RouterNode[int]) string {
+	id := len(generator.functions)
+	generator.functions = append(generator.functions, "")
+	method := fmt.Sprintf("node%d", id)
+	children := generator.walk(node.nodes)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "// %s {%s:%s}\n", method, node.name, node.rule)
+	fmt.Fprintf(&body, "func (m *%s[T]) %s(value string, params contracts.RouteParams) (T, bool) {\n", generator.name, method)
+
+	if node.terminal {
+		condition := "!strings.Contains(value, \"/\")"
+		if check := generator.check(node.rule, "value"); check != "" && node.optional {
+			condition += fmt.Sprintf(" && (%s || value == \"\")", check)
+		} else if check != "" {
+			condition += " && " + check
+		}
+		fmt.Fprintf(&body, "if %s {\nif result, found := m.accept(%d, %q, value, params); found {\nreturn result, true\n}\n}\n",
+			condition, node.data, node.name)
+	}
+
+	for _, sub := range sortedPrefixes(node.nodes) {
+		slot := -1
+		if data, isEnd := node.suffixes[sub]; isEnd {
+			slot = data
+		}
+		descend := func(value, rest string) string {
+			return fmt.Sprintf("if result, found := m.descend(%q, %s, %s, %q, %d, %t, m.%s, params); found {\nreturn result, true\n}\n",
+				node.name, value, rest, sub, slot, len(node.nodes[sub]) > 0, children)
+		}
+
+		var general strings.Builder
+		condition := "!strings.Contains(head, \"/\")"
+		if check := generator.check(node.rule, "head"); check != "" && !node.optional {
+			condition += " && " + check
+		}
+		fmt.Fprintf(&general, "if index := strings.Index(value, %q); index > -1 {\n", sub)
+		fmt.Fprintf(&general, "if head := value[:index]; %s {\n%s}\n}", condition, descend("head", "value[index:]"))
+		if slot >= 0 && node.optional {
+			fmt.Fprintf(&general, " else if \"/\"+value == %q {\nif result, found := m.accept(%d, %q, \"\", params); found {\nreturn result, true\n}\n}", sub, slot, node.name)
+		}
+		general.WriteString("\n")
+
+		if sub != "/" {
+			body.WriteString(general.String())
+			continue
+		}
+		if check := generator.check(node.rule, "head"); check == "" {
+			fmt.Fprintf(&body, "{\nhead, rest := %sSegment(value)\n%s}\n", generator.prefix, descend("head", "rest"))
+		} else {
+			fmt.Fprintf(&body, "if head, rest := %sSegment(value); %s {\n%s} else {\n%s}\n", generator.prefix, check, descend("head", "rest"), general.String())
+		}
+	}
+	body.WriteString("var zero T\nreturn zero, false\n}\n\n")
+
+	generator.functions[id] = body.String()
+	return method
+}
+
+// check 返回检查 value 是否满足约束的表达式，约束总是满足时返回空字符串。
+// 与 Router 一致，约束不带 ^ 和 $ 时只要求包含匹配的内容
+func (generator *matcherGenerator) check(rule, value string) string {
+	function, exists := generator.rules[rule]
+	if !exists {
+		function = generator.compileRule(rule)
+		generator.rules[rule] = function
+	}
+	if function == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s(%s)", function, value)
+}
+
+func (generator *matcherGenerator) compileRule(rule string) string {
+	if rule == "" || rule == ".*" {
+		return ""
+	}
+
+	function := fmt.Sprintf("%sRule%d", generator.prefix, len(generator.ruleFuncs))
+	pattern := strings.TrimSuffix(strings.TrimPrefix(rule, "^"), "$")
+	anchored := strings.HasPrefix(rule, "^") && strings.HasSuffix(rule, "$")
+	if anchored || pattern == rule {
+		class, quantifier := pattern, ""
+		if strings.HasSuffix(class, "+") || strings.HasSuffix(class, "*") {
+			class, quantifier = class[:len(class)-1], class[len(class)-1:]
+		}
+		if condition, ok := byteClassCondition(class); ok && quantifier != "" {
+			switch {
+			case !anchored && quantifier == "*":
+				return ""
+			case !anchored:
+				generator.ruleFuncs = append(generator.ruleFuncs, fmt.Sprintf(
+					"// %s %s\nfunc %s(value string) bool {\nfor i := 0; i < len(value); i++ {\nif c := value[i]; %s {\nreturn true\n}\n}\nreturn false\n}\n\n",
+					function, rule, function, condition))
+			default:
+				empty := "true"
+				if quantifier == "+" {
+					empty = "len(value) > 0"
+				}
+				generator.ruleFuncs = append(generator.ruleFuncs, fmt.Sprintf(
+					"// %s %s\nfunc %s(value string) bool {\nfor i := 0; i < len(value); i++ {\nif c := value[i]; !(%s) {\nreturn false\n}\n}\nreturn %s\n}\n\n",
+					function, rule, function, condition, empty))
+			}
+			return function
+		}
+	}
+
+	generator.regexp = true
+	reg := fmt.Sprintf("%sReg%d", generator.prefix, len(generator.ruleFuncs))
+	generator.ruleFuncs = append(generator.ruleFuncs, fmt.Sprintf(
+		"var %s = regexp.MustCompile(%q)\n\n// %s %s\nfunc %s(value string) bool {\nreturn %s.MatchString(value)\n}\n\n",
+		reg, rule, function, rule, function, reg))
+	return function
+}
+
+// byteClassCondition 把 [0-9a-z_]、\d 这样只包含 ASCII 字符的字符类转换为比较字节 c 的条件
+func byteClassCondition(class string) (string, bool) {
+	if class == `\d` {
+		class = "[0-9]"
+	}
+	if len(class) < 3 || class[0] != '[' || class[len(class)-1] != ']' {
+		return "", false
+	}
+
+	var conditions []string
+	items := class[1 : len(class)-1]
+	isLiteral := func(c byte) bool {
+		return c < unicode.MaxASCII && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' || c == '.')
+	}
+	for i := 0; i < len(items); i++ {
+		switch {
+		case i+2 < len(items) && items[i+1] == '-' && isLiteral(items[i]) && isLiteral(items[i+2]):
+			conditions = append(conditions, fmt.Sprintf("(c >= %q && c <= %q)", items[i], items[i+2]))
+			i += 2
+		case isLiteral(items[i]) || (items[i] == '-' && (i == 0 || i == len(items)-1)):
+			conditions = append(conditions, fmt.Sprintf("c == %q", items[i]))
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(conditions, " || "), true
+}
+
+// MatcherRoutesFromCache 从 HttpRouter.Cache 生成的缓存中读取路由路径，method 为空时返回所有方法的路由，前缀路由不包含在内
+func MatcherRoutesFromCache(r io.Reader, method string) ([]string, error) {
+	var cache routeCache
+	if err := json.NewDecoder(r).Decode(&cache); err != nil {
+		return nil, fmt.Errorf("invalid route cache: %w", err)
+	}
+
+	var paths []string
+	for _, route := range cache.Routes {
+		if !route.Prefix && (method == "" || containsString(route.Methods, strings.ToUpper(method))) {
+			paths = append(paths, route.Path)
+		}
+	}
+	return unique(paths), nil
+}
+
+// MatcherRoutesFromConfig 从路由配置中读取路由路径，method 为空时返回所有方法的路由，跳过禁用的路由
+func MatcherRoutesFromConfig(routes []RouteConfig, method string) []string {
+	var paths []string
+	for _, route := range routes {
+		if !route.Disabled && (method == "" || containsString(route.methods(), strings.ToUpper(method))) {
+			paths = append(paths, route.Path)
+		}
+	}
+	return unique(paths)
+}
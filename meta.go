@@ -0,0 +1,70 @@
+package routing
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+// With 设置路由元数据，例如负责的团队、鉴权范围、限流等级，覆盖所在组的同名元数据
+func (route *Route) With(key string, value any) contracts.Route {
+	if route.meta == nil {
+		route.meta = map[string]any{}
+	}
+	route.meta[key] = value
+	return route
+}
+
+// Meta 返回路由的元数据，包含从各级组继承的元数据，越具体的同名元数据优先
+func (route *Route) Meta() map[string]any {
+	var meta map[string]any
+	if route.group != nil {
+		meta = route.group.Meta()
+	} else {
+		meta = map[string]any{}
+	}
+	for key, value := range route.meta {
+		meta[key] = value
+	}
+	return meta
+}
+
+// With 设置组的元数据，组内的路由和子组都会继承
+func (group *Group) With(key string, value any) contracts.RouteGroup {
+	if group.meta == nil {
+		group.meta = map[string]any{}
+	}
+	group.meta[key] = value
+	return group
+}
+
+// Meta 返回组的元数据，包含从上级组继承的元数据
+func (group *Group) Meta() map[string]any {
+	var meta map[string]any
+	if group.parent != nil {
+		meta = group.parent.Meta()
+	} else {
+		meta = map[string]any{}
+	}
+	for key, value := range group.meta {
+		meta[key] = value
+	}
+	return meta
+}
+
+// MetaProvider 提供元数据的路由
+type MetaProvider interface {
+	Meta() map[string]any
+}
+
+// RouteMeta 返回路由的元数据，路由不支持元数据时返回空
+func RouteMeta(route contracts.Route) map[string]any {
+	if provider, ok := route.(MetaProvider); ok {
+		return provider.Meta()
+	}
+	return map[string]any{}
+}
+
+// MetaValue 返回指定类型的路由元数据，不存在或者类型不符时 ok 为 false
+func MetaValue[T any](route contracts.Route, key string) (value T, ok bool) {
+	value, ok = RouteMeta(route)[key].(T)
+	return
+}
@@ -0,0 +1,341 @@
+package routing
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MiddlewareParams 通过别名引用中间件时传入的参数，例如 throttle:60,1 的参数为 ["60", "1"]，
+// 中间件声明该类型的参数即可获得
+type MiddlewareParams []string
+
+var middlewareParamsType = reflect.TypeOf(MiddlewareParams{})
+
+// middlewareReference 以字符串引用的中间件，Mount 时解析为注册的别名或者中间件组，未解析前不能调用
+type middlewareReference struct {
+	contracts.MagicalFunc
+	name   string
+	params MiddlewareParams
+}
+
+func newMiddlewareReference(reference string) *middlewareReference {
+	name, params, hasParams := strings.Cut(reference, ":")
+	ref := &middlewareReference{name: name}
+	if hasParams {
+		ref.params = strings.Split(params, ",")
+	}
+	return ref
+}
+
+func (ref *middlewareReference) NumOut() int {
+	return 1
+}
+
+// aliasMiddleware 由别名解析出来的中间件，调用时把引用参数注入到 MiddlewareParams 类型的参数中
+type aliasMiddleware struct {
+	contracts.MagicalFunc
+	name        string
+	params      MiddlewareParams
+	paramsIndex int
+}
+
+func newAliasMiddleware(name string, middleware contracts.MagicalFunc, params MiddlewareParams) *aliasMiddleware {
+	alias := &aliasMiddleware{MagicalFunc: middleware, name: name, params: params, paramsIndex: -1}
+	for i, argType := range middleware.Arguments() {
+		if argType == middlewareParamsType {
+			alias.paramsIndex = i
+			break
+		}
+	}
+	return alias
+}
+
+func (alias *aliasMiddleware) Arguments() []reflect.Type {
+	arguments := alias.MagicalFunc.Arguments()
+	if alias.paramsIndex < 0 {
+		return arguments
+	}
+	return append(append([]reflect.Type{}, arguments[:alias.paramsIndex]...), arguments[alias.paramsIndex+1:]...)
+}
+
+func (alias *aliasMiddleware) NumIn() int {
+	if alias.paramsIndex < 0 {
+		return alias.MagicalFunc.NumIn()
+	}
+	return alias.MagicalFunc.NumIn() - 1
+}
+
+func (alias *aliasMiddleware) Call(args []reflect.Value) []reflect.Value {
+	if alias.paramsIndex < 0 {
+		return alias.MagicalFunc.Call(args)
+	}
+	in := append(append([]reflect.Value{}, args[:alias.paramsIndex]...), reflect.ValueOf(alias.params))
+	return alias.MagicalFunc.Call(append(in, args[alias.paramsIndex:]...))
+}
+
+func (alias *aliasMiddleware) Terminator() contracts.MagicalFunc {
+	if terminator, ok := alias.MagicalFunc.(Terminator); ok {
+		return terminator.Terminator()
+	}
+	return nil
+}
+
+// MiddlewareName 返回中间件的别名，没有别名时返回空字符串
+func MiddlewareName(middleware contracts.MagicalFunc) string {
+	switch value := middleware.(type) {
+	case *aliasMiddleware:
+		return value.name
+	case *middlewareReference:
+		return value.name
+	}
+	return ""
+}
+
+// AliasMiddleware 注册一个中间件别名，之后可以用 "name" 或者 "name:param1,param2" 引用该中间件
+func (httpRouter *HttpRouter) AliasMiddleware(name string, middleware any) {
+	httpRouter.middlewareAliases[name] = ConvertToMiddlewares(middleware)[0]
+}
+
+// MiddlewareGroup 注册一个中间件组，引用组名等同于按顺序引用组内所有中间件，组内可以继续引用别名和其他组
+func (httpRouter *HttpRouter) MiddlewareGroup(name string, middlewares ...any) {
+	httpRouter.middlewareGroups[name] = ConvertToMiddlewares(middlewares...)
+}
+
+// resolveMiddlewares 把字符串引用替换为注册的中间件，无法解析的引用原样保留并返回其名称
+func (httpRouter *HttpRouter) resolveMiddlewares(middlewares []contracts.MagicalFunc) ([]contracts.MagicalFunc, []string) {
+	return httpRouter.resolve(middlewares, map[string]bool{})
+}
+
+func (httpRouter *HttpRouter) resolve(middlewares []contracts.MagicalFunc, resolving map[string]bool) ([]contracts.MagicalFunc, []string) {
+	var results = make([]contracts.MagicalFunc, 0, len(middlewares))
+	var unknown []string
+	for _, middleware := range middlewares {
+		ref, isReference := middleware.(*middlewareReference)
+		if !isReference {
+			results = append(results, middleware)
+			continue
+		}
+
+		if group, isGroup := httpRouter.middlewareGroups[ref.name]; isGroup && !resolving[ref.name] {
+			resolving[ref.name] = true
+			groupMiddlewares, groupUnknown := httpRouter.resolve(group, resolving)
+			delete(resolving, ref.name)
+			results = append(results, groupMiddlewares...)
+			unknown = append(unknown, groupUnknown...)
+		} else if alias, isAlias := httpRouter.middlewareAliases[ref.name]; isAlias {
+			results = append(results, newAliasMiddleware(ref.name, alias, ref.params))
+		} else {
+			results = append(results, ref)
+			unknown = append(unknown, ref.name)
+		}
+	}
+	return results, unknown
+}
+
+// resolveEntries 解析全局中间件、挂载中间件以及所有路由的中间件引用
+func (httpRouter *HttpRouter) resolveEntries(entries []routeEntry) []string {
+	var unknown []string
+	var tmpUnknown []string
+
+	httpRouter.middlewares, tmpUnknown = httpRouter.resolveMiddlewares(httpRouter.middlewares)
+	unknown = append(unknown, tmpUnknown...)
+
+	for _, mounted := range httpRouter.mountedRouters {
+		mounted.middlewares, tmpUnknown = httpRouter.resolveMiddlewares(mounted.middlewares)
+		unknown = append(unknown, tmpUnknown...)
+	}
+
+	for _, entry := range entries {
+		if route, isRoute := entry.route.(*Route); isRoute {
+			// 只从继承的中间件中排除，路由自身声明的中间件总是保留
+			inherited, own := route.splitMiddlewares()
+			inherited, tmpUnknown = httpRouter.resolveMiddlewares(inherited)
+			unknown = append(unknown, tmpUnknown...)
+			own, tmpUnknown = httpRouter.resolveMiddlewares(own)
+			unknown = append(unknown, tmpUnknown...)
+
+			inherited = withoutMiddlewares(inherited, route.excluded())
+			route.middlewares = httpRouter.sortMiddlewares(mergeMiddlewares(inherited, own))
+			route.inherited = len(inherited)
+			route.stack = httpRouter.effectiveMiddlewares(route)
+		}
+	}
+
+	return unique(unknown)
+}
+
+// RouteMiddlewares 返回路由最终生效的中间件的副本：排除后的全局中间件加上路由自身的中间件，需要在 Mount 之后调用
+func (httpRouter *HttpRouter) RouteMiddlewares(route contracts.Route) []contracts.MagicalFunc {
+	return mergeMiddlewares(httpRouter.routeStack(route))
+}
+
+// routeStack 返回路由最终生效的中间件，Mount 时已经计算好的直接返回，不能修改返回值
+func (httpRouter *HttpRouter) routeStack(route contracts.Route) []contracts.MagicalFunc {
+	if original, isRoute := route.(*Route); isRoute && original.stack != nil {
+		return original.stack
+	}
+	return httpRouter.effectiveMiddlewares(route)
+}
+
+// effectiveMiddlewares 计算路由最终生效的中间件
+func (httpRouter *HttpRouter) effectiveMiddlewares(route contracts.Route) []contracts.MagicalFunc {
+	globals := httpRouter.middlewares
+	if original, isRoute := unwrapRoute(route).(*Route); isRoute {
+		globals = withoutMiddlewares(globals, original.excluded())
+	}
+	own := route.Middlewares()
+	if original, isRoute := route.(*Route); isRoute {
+		own = original.middlewares
+	}
+	return httpRouter.sortMiddlewares(mergeMiddlewares(globals, own))
+}
+
+// MiddlewareNames 返回匹配 method 和 path 的路由最终生效的中间件别名，没有别名的中间件为空字符串
+func (httpRouter *HttpRouter) MiddlewareNames(method, path string) ([]string, error) {
+	route, _, err := httpRouter.Route(method, &url.URL{Path: path})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, middleware := range httpRouter.RouteMiddlewares(route) {
+		names = append(names, MiddlewareName(middleware))
+	}
+	return names, nil
+}
+
+// MiddlewarePriority 设置中间件优先级，列表中的中间件（别名或者中间件实例）在每个路由最终生效的中间件中按列表顺序排列，
+// 不在列表中的中间件保持注册顺序和位置不变
+func (httpRouter *HttpRouter) MiddlewarePriority(middlewares ...any) {
+	httpRouter.middlewarePriority = middlewares
+}
+
+// sortMiddlewares 只在优先级列表中的中间件所占的位置之间重新排序
+func (httpRouter *HttpRouter) sortMiddlewares(middlewares []contracts.MagicalFunc) []contracts.MagicalFunc {
+	if len(httpRouter.middlewarePriority) == 0 {
+		return middlewares
+	}
+
+	var positions []int
+	var priorities = map[int]int{}
+	for i, middleware := range middlewares {
+		for priority, item := range httpRouter.middlewarePriority {
+			if isSameMiddleware(middleware, item) {
+				positions = append(positions, i)
+				priorities[i] = priority
+				break
+			}
+		}
+	}
+
+	sorted := append([]int{}, positions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return priorities[sorted[i]] < priorities[sorted[j]]
+	})
+
+	var results = append([]contracts.MagicalFunc{}, middlewares...)
+	for i, position := range positions {
+		results[position] = middlewares[sorted[i]]
+	}
+	return results
+}
+
+// withoutMiddlewares 返回排除了 excluded 之后的中间件
+func withoutMiddlewares(middlewares []contracts.MagicalFunc, excluded []any) []contracts.MagicalFunc {
+	if len(excluded) == 0 {
+		return middlewares
+	}
+	var results = make([]contracts.MagicalFunc, 0, len(middlewares))
+	for _, middleware := range middlewares {
+		var isExcluded bool
+		for _, item := range excluded {
+			if isSameMiddleware(middleware, item) {
+				isExcluded = true
+				break
+			}
+		}
+		if !isExcluded {
+			results = append(results, middleware)
+		}
+	}
+	return results
+}
+
+// Exclusion 由 Without 创建，注册路由或者组时和中间件一起传入，表示从继承的中间件中排除指定的中间件
+type Exclusion struct {
+	middlewares []any
+}
+
+// Without 创建一个排除中间件的标记，例如 group.Post("/hooks", handler, routing.Without("auth"))，
+// 效果等同于对路由或者组调用 WithoutMiddleware
+func Without(middlewares ...any) Exclusion {
+	return Exclusion{middlewares: checkExcluded(middlewares)}
+}
+
+// splitExclusions 把中间件参数中的 Without 标记分离出来，返回剩下的中间件和需要排除的中间件
+func splitExclusions(middlewares []any) ([]any, []any) {
+	var results = make([]any, 0, len(middlewares))
+	var excluded []any
+	for _, middleware := range middlewares {
+		if exclusion, isExclusion := middleware.(Exclusion); isExclusion {
+			excluded = append(excluded, exclusion.middlewares...)
+		} else {
+			results = append(results, middleware)
+		}
+	}
+	return results, excluded
+}
+
+// checkExcluded 排除的中间件只能是别名、中间件实例或者函数，其他类型无法比较，直接 panic 而不是静默忽略
+func checkExcluded(middlewares []any) []any {
+	for _, middleware := range middlewares {
+		switch middleware.(type) {
+		case string, contracts.MagicalFunc:
+		default:
+			if reflect.ValueOf(middleware).Kind() != reflect.Func {
+				panic(ExcludedMiddlewareError)
+			}
+		}
+	}
+	return middlewares
+}
+
+// isSameMiddleware 字符串按别名比较（忽略参数），中间件实例按同一实例比较，函数按函数指针比较
+func isSameMiddleware(middleware contracts.MagicalFunc, target any) bool {
+	switch value := target.(type) {
+	case string:
+		name, _, _ := strings.Cut(value, ":")
+		return name != "" && MiddlewareName(middleware) == name
+	case contracts.MagicalFunc:
+		if alias, isAlias := middleware.(*aliasMiddleware); isAlias && alias.MagicalFunc == value {
+			return true
+		}
+		return middleware == value
+	}
+	if fn := reflect.ValueOf(target); fn.Kind() == reflect.Func {
+		pointer, exists := funcPointer(middleware)
+		return exists && pointer == fn.Pointer()
+	}
+	return false
+}
+
+func unique(items []string) []string {
+	var exists = map[string]bool{}
+	var results []string
+	for _, item := range items {
+		if !exists[item] {
+			exists[item] = true
+			results = append(results, item)
+		}
+	}
+	sort.Strings(results)
+	return results
+}
+
+func unknownMiddlewareError(names []string) error {
+	return fmt.Errorf("unknown middleware [%s]", strings.Join(names, "|"))
+}
@@ -11,6 +11,11 @@ type RouterNode[T any] struct {
 	rule     string
 	reg      *regexp.Regexp
 	nodes    map[string][]*RouterNode[T]
+	prefixes []string // nodes 的前缀，已按优先级排序
+
+	// terminal 为 true 时有路由以该参数结尾，数据为 data；suffixes 保存以该参数加静态后缀结尾的路由数据
+	terminal bool
+	suffixes map[string]T
 }
 
 func NewRouteNode[T any](param string, data T) *RouterNode[T] {
@@ -22,6 +27,7 @@ func NewRouteNode[T any](param string, data T) *RouterNode[T] {
 		name:     name,
 		reg:      regexp.MustCompile(rule),
 		nodes:    make(map[string][]*RouterNode[T]),
+		suffixes: make(map[string]T),
 	}
 }
 
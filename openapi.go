@@ -0,0 +1,365 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	OpenAPIPathConflictErr = errors.New("openapi path conflict") // 不同路由生成了相同的路径和方法，例如可选参数省略后与另一个路由相同
+)
+
+// OpenAPIDocument OpenAPI 3.1 文档
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationId string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// OpenAPI 根据已注册的路由生成 OpenAPI 3.1 文档，前缀路由不会出现在文档中。
+// 路径参数的约束作为 pattern，可选参数会生成带参数和不带参数两条路径；路由名作为 operationId，
+// 元数据 summary 和 deprecated 作为操作的摘要和废弃标记；处理器的结构体参数作为请求体，结构体返回值作为响应。
+// 不同路由生成相同的路径和方法时返回 OpenAPIPathConflictErr
+func (httpRouter *HttpRouter) OpenAPI(info OpenAPIInfo) (*OpenAPIDocument, error) {
+	generator := &openAPIGenerator{schemas: map[string]*OpenAPISchema{}, names: map[reflect.Type]string{}}
+	document := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+	owners := map[string]string{}
+
+	for _, entry := range httpRouter.entries() {
+		if entry.prefix {
+			continue
+		}
+		route := entry.route
+		meta := RouteMeta(route)
+		for _, variant := range openAPIPathVariants(route.GetPath()) {
+			if document.Paths[variant.path] == nil {
+				document.Paths[variant.path] = map[string]*OpenAPIOperation{}
+			}
+			for _, method := range route.Method() {
+				key := method + " " + variant.path
+				if owner, exists := owners[key]; exists && owner != route.GetPath() {
+					return nil, fmt.Errorf("%w: %s is generated by both %s and %s", OpenAPIPathConflictErr, key, owner, route.GetPath())
+				}
+				owners[key] = route.GetPath()
+				operation := generator.operation(handlerArguments(route.Handler()), route.Handler().Returns(), method)
+				operation.Parameters = variant.parameters
+				operation.OperationId = openAPIOperationId(route.GetName(), method, len(route.Method()) > 1, variant.omitted)
+				operation.Summary, _ = meta["summary"].(string)
+				operation.Deprecated, _ = meta["deprecated"].(bool)
+				document.Paths[variant.path][strings.ToLower(method)] = operation
+			}
+		}
+	}
+
+	if len(generator.schemas) > 0 {
+		document.Components = &OpenAPIComponents{Schemas: generator.schemas}
+	}
+	return document, nil
+}
+
+// WriteOpenAPI 把 OpenAPI 文档以 json 格式写入 w
+func (httpRouter *HttpRouter) WriteOpenAPI(w io.Writer, info OpenAPIInfo) error {
+	document, err := httpRouter.OpenAPI(info)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+type openAPIPathVariant struct {
+	path       string
+	parameters []*OpenAPIParameter
+	omitted    []string
+}
+
+// openAPIPathVariants 把 {name:rule} 形式的路径转换为 OpenAPI 路径，每个可选参数分别生成保留和省略两种路径
+func openAPIPathVariants(template string) []openAPIPathVariant {
+	params := paramReg.FindAllString(template, -1)
+	var optional []string
+	for _, param := range params {
+		if name, _, isOptional := parseRule(param); isOptional {
+			optional = append(optional, name)
+		}
+	}
+
+	var variants []openAPIPathVariant
+	for mask := 0; mask < 1<<len(optional); mask++ {
+		omitted := map[string]bool{}
+		var variant openAPIPathVariant
+		for i, name := range optional {
+			if mask&(1<<i) != 0 {
+				omitted[name] = true
+				variant.omitted = append(variant.omitted, name)
+			}
+		}
+
+		path := paramReg.ReplaceAllStringFunc(template, func(param string) string {
+			name, rule, _ := parseRule(param)
+			if omitted[name] {
+				return ""
+			}
+			schema := &OpenAPISchema{Type: "string"}
+			if rule != ".*" {
+				schema.Pattern = rule
+			}
+			variant.parameters = append(variant.parameters, &OpenAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+			return "{" + name + "}"
+		})
+		for strings.Contains(path, "//") {
+			path = strings.ReplaceAll(path, "//", "/")
+		}
+		variant.path = trimPath(path)
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+func openAPIOperationId(name, method string, multipleMethods bool, omitted []string) string {
+	if name == "" {
+		return ""
+	}
+	if multipleMethods {
+		name += "." + strings.ToLower(method)
+	}
+	if len(omitted) > 0 {
+		name += ".without_" + strings.Join(omitted, "_")
+	}
+	return name
+}
+
+type openAPIGenerator struct {
+	schemas map[string]*OpenAPISchema
+	names   map[reflect.Type]string // 结构体在 components 中的名称
+}
+
+func (generator *openAPIGenerator) operation(arguments, returns []reflect.Type, method string) *OpenAPIOperation {
+	operation := &OpenAPIOperation{
+		Responses: map[string]*OpenAPIResponse{"200": {Description: http.StatusText(http.StatusOK)}},
+	}
+
+	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+		for _, argument := range arguments {
+			if isPayloadStruct(argument) {
+				operation.RequestBody = &OpenAPIRequestBody{
+					Required: true,
+					Content:  map[string]*OpenAPIMediaType{"application/json": {Schema: generator.schema(argument)}},
+				}
+				break
+			}
+		}
+	}
+
+	if len(returns) > 0 {
+		result := returns[0]
+		switch {
+		case result.Kind() == reflect.String:
+			operation.Responses["200"].Content = map[string]*OpenAPIMediaType{"text/plain": {Schema: &OpenAPISchema{Type: "string"}}}
+		case isPayloadStruct(result) || isPayloadStruct(elemType(result)):
+			operation.Responses["200"].Content = map[string]*OpenAPIMediaType{"application/json": {Schema: generator.schema(result)}}
+		}
+	}
+	return operation
+}
+
+// schema 通过反射生成类型的 json schema，具名结构体放到 components 中引用
+func (generator *openAPIGenerator) schema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: generator.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: generator.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return generator.structSchema(t)
+		}
+		name, exists := generator.names[t]
+		if !exists {
+			name = generator.schemaName(t)
+			generator.names[t] = name
+			generator.schemas[name] = &OpenAPISchema{}
+			*generator.schemas[name] = *generator.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return &OpenAPISchema{}
+}
+
+// schemaName 优先使用结构体名，不同包的同名结构体使用包路径加结构体名，仍然重复时加上序号
+func (generator *openAPIGenerator) schemaName(t reflect.Type) string {
+	name := openAPIComponentName(t.Name())
+	if _, exists := generator.schemas[name]; !exists {
+		return name
+	}
+
+	name = openAPIComponentName(strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name())
+	candidate := name
+	for i := 2; generator.schemas[candidate] != nil; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	return candidate
+}
+
+// openAPIComponentName 把 components 名称中不允许的字符替换为 _，例如泛型结构体名中的 [ 和 ]
+func openAPIComponentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (generator *openAPIGenerator) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = generator.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// isPayloadStruct 判断类型是否为业务结构体，排除 net/http 和 goal-web 自身的类型
+func isPayloadStruct(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	pkgPath := t.PkgPath()
+	if strings.HasPrefix(pkgPath, "net/") {
+		return false
+	}
+	for _, framework := range frameworkPackages {
+		if pkgPath == framework {
+			return false
+		}
+	}
+	return true
+}
+
+var frameworkPackages = []string{
+	"github.com/goal-web/contracts",
+	"github.com/goal-web/container",
+	"github.com/goal-web/routing",
+	"github.com/goal-web/http",
+}
+
+// handlerArguments 返回处理器需要注入的参数类型，控制器方法的第一个参数是控制器本身，不包含在内
+func handlerArguments(handler contracts.MagicalFunc) []reflect.Type {
+	arguments := handler.Arguments()
+	if _, isAction := handler.(*controllerAction); isAction && len(arguments) > 0 {
+		return arguments[1:]
+	}
+	return arguments
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return t.Elem()
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	NotImplementedErr = errors.New("not implemented") // 生成的处理器桩函数返回该错误，RenderError 响应 501
+)
+
+// HandlerRegistry operationId 到处理器的映射
+type HandlerRegistry map[string]any
+
+// openAPIContract 契约优先模式下的 OpenAPI 文档，Mount 时校验路由和文档是否一致
+type openAPIContract struct {
+	document   *OpenAPIDocument
+	operations map[string]bool // [METHOD] path，path 中的参数不带约束
+	missing    []string
+}
+
+// LoadOpenAPI 读取 json 或者 yaml 格式的 OpenAPI 文档，路径级别的参数会合并到每个操作中。
+// 不同操作的 operationId 重复时返回错误
+func LoadOpenAPI(reader io.Reader) (*OpenAPIDocument, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if content = bytes.TrimSpace(content); !bytes.HasPrefix(content, []byte("{")) {
+		if content, err = openAPIYamlToJson(content); err != nil {
+			return nil, err
+		}
+	}
+
+	var raw struct {
+		OpenAPI    string                                `json:"openapi"`
+		Info       OpenAPIInfo                           `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components *OpenAPIComponents                    `json:"components"`
+	}
+	if err = json.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	document := &OpenAPIDocument{
+		OpenAPI:    raw.OpenAPI,
+		Info:       raw.Info,
+		Paths:      map[string]map[string]*OpenAPIOperation{},
+		Components: raw.Components,
+	}
+	for path, item := range raw.Paths {
+		var pathParameters []*OpenAPIParameter
+		if parameters, exists := item["parameters"]; exists {
+			if err := json.Unmarshal(parameters, &pathParameters); err != nil {
+				return nil, fmt.Errorf("paths.%s.parameters: %w", path, err)
+			}
+		}
+
+		document.Paths[path] = map[string]*OpenAPIOperation{}
+		for _, method := range methodList {
+			content, exists := item[strings.ToLower(method)]
+			if !exists {
+				continue
+			}
+			var operation OpenAPIOperation
+			if err := json.Unmarshal(content, &operation); err != nil {
+				return nil, fmt.Errorf("paths.%s.%s: %w", path, strings.ToLower(method), err)
+			}
+			for _, parameter := range pathParameters {
+				if findOpenAPIParameter(operation.Parameters, parameter.Name, parameter.In) == nil {
+					operation.Parameters = append(operation.Parameters, parameter)
+				}
+			}
+			document.Paths[path][strings.ToLower(method)] = &operation
+		}
+	}
+	return document, validateOperationIds(document)
+}
+
+// openAPIYamlToJson 把 yaml 文档转换为 json，yaml 中 200 这样的非字符串键转换为字符串
+func openAPIYamlToJson(content []byte) ([]byte, error) {
+	var document any
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalizeYamlValue(document))
+}
+
+func normalizeYamlValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = normalizeYamlValue(item)
+		}
+		return value
+	case map[any]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			result[fmt.Sprint(key)] = normalizeYamlValue(item)
+		}
+		return result
+	case []any:
+		for i, item := range value {
+			value[i] = normalizeYamlValue(item)
+		}
+		return value
+	}
+	return value
+}
+
+// validateOperationIds 检查 operationId 是否唯一，operationId 同时用作路由名和处理器桩函数名
+func validateOperationIds(document *OpenAPIDocument) error {
+	operations := map[string]string{}
+	var duplicates []string
+	for path, item := range document.Paths {
+		for _, method := range methodList {
+			operation, exists := item[strings.ToLower(method)]
+			if !exists || operation.OperationId == "" {
+				continue
+			}
+			key := fmt.Sprintf("[%s] %s", method, path)
+			if existing, exists := operations[operation.OperationId]; exists {
+				duplicates = append(duplicates, fmt.Sprintf("%s (%s, %s)", operation.OperationId, existing, key))
+				continue
+			}
+			operations[operation.OperationId] = key
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return fmt.Errorf("duplicate operationId [%s]", strings.Join(duplicates, "|"))
+	}
+	return nil
+}
+
+// LoadOpenAPIFile 读取 json 或者 yaml 格式的 OpenAPI 文件
+func LoadOpenAPIFile(filename string) (*OpenAPIDocument, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	document, err := LoadOpenAPI(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return document, nil
+}
+
+// RegisterOpenAPI 按 OpenAPI 文档注册路由，每个操作的处理器通过 operationId 在 handlers 中查找，路由名为 operationId。
+// 路径参数的 pattern 会转换为路由约束。之后 Mount 时，如果有操作找不到处理器，或者注册了文档中没有描述的路由，Mount 会返回错误
+func (httpRouter *HttpRouter) RegisterOpenAPI(document *OpenAPIDocument, handlers HandlerRegistry) {
+	contract := &openAPIContract{document: document, operations: map[string]bool{}}
+
+	paths := make([]string, 0, len(document.Paths))
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, method := range methodList {
+			operation, exists := document.Paths[path][strings.ToLower(method)]
+			if !exists {
+				continue
+			}
+			contract.operations[operationKey(method, path)] = true
+
+			handler, exists := handlers[operation.OperationId]
+			if operation.OperationId == "" || !exists {
+				contract.missing = append(contract.missing, fmt.Sprintf("[%s] %s (%s)", method, path, operation.OperationId))
+				continue
+			}
+
+			route := httpRouter.Add(method, openAPIRoutePath(path, operation.Parameters), handler)
+			route.Name(operation.OperationId)
+			if original, isRoute := route.(*Route); isRoute {
+				if operation.Summary != "" {
+					original.With("summary", operation.Summary)
+				}
+				if operation.Deprecated {
+					original.With("deprecated", true)
+				}
+			}
+		}
+	}
+
+	httpRouter.contract = contract
+}
+
+// validate 返回契约校验失败的原因
+func (contract *openAPIContract) validate(entries []routeEntry) []string {
+	var failures []string
+	if len(contract.missing) > 0 {
+		failures = append(failures, fmt.Sprintf("operations without handler [%s]", strings.Join(contract.missing, "|")))
+	}
+
+	var undocumented []string
+	for _, entry := range entries {
+		if entry.prefix {
+			continue
+		}
+		for _, method := range entry.route.Method() {
+			if !contract.operations[operationKey(method, entry.route.GetPath())] {
+				undocumented = append(undocumented, fmt.Sprintf("[%s] %s%s", method, entry.route.GetPath(), entry.describe()))
+			}
+		}
+	}
+	if len(undocumented) > 0 {
+		failures = append(failures, fmt.Sprintf("routes not described by the OpenAPI document [%s]", strings.Join(undocumented, "|")))
+	}
+	return failures
+}
+
+// operationKey 生成 [METHOD] path 形式的键，去掉参数约束，使路由路径和 OpenAPI 路径可以比较
+func operationKey(method, path string) string {
+	path = paramReg.ReplaceAllStringFunc(path, func(param string) string {
+		name, _, _ := parseRule(param)
+		return "{" + name + "}"
+	})
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	return fmt.Sprintf("[%s] %s", method, trimPath(path))
+}
+
+// openAPIRoutePath 把 OpenAPI 路径转换为路由路径，带 pattern 的路径参数转换为 {name:pattern}
+func openAPIRoutePath(path string, parameters []*OpenAPIParameter) string {
+	return paramReg.ReplaceAllStringFunc(path, func(param string) string {
+		name := param[1 : len(param)-1]
+		if parameter := findOpenAPIParameter(parameters, name, "path"); parameter != nil && parameter.Schema != nil && parameter.Schema.Pattern != "" {
+			return "{" + name + ":" + parameter.Schema.Pattern + "}"
+		}
+		return param
+	})
+}
+
+func findOpenAPIParameter(parameters []*OpenAPIParameter, name, in string) *OpenAPIParameter {
+	for _, parameter := range parameters {
+		if parameter.Name == name && parameter.In == in {
+			return parameter
+		}
+	}
+	return nil
+}
+
+// GenerateOpenAPIStubs 为 OpenAPI 文档中的每个操作生成处理器桩函数和对应的 HandlerRegistry，输出为格式化后的 Go 源码。
+// 桩函数返回 NotImplementedErr，未实现的接口响应 501
+func GenerateOpenAPIStubs(w io.Writer, document *OpenAPIDocument, packageName string) error {
+	if err := validateOperationIds(document); err != nil {
+		return err
+	}
+
+	var source strings.Builder
+	source.WriteString("// 由 OpenAPI 文档生成的处理器桩函数，实现各个处理器后通过 Handlers 注册到 HttpRouter\n\n")
+	fmt.Fprintf(&source, "package %s\n\nimport (\n\"fmt\"\n\"github.com/goal-web/routing\"\n)\n\n", packageName)
+
+	type stub struct{ operationId, function, method, path, summary string }
+	var stubs []stub
+	for path, operations := range document.Paths {
+		for _, method := range methodList {
+			if operation, exists := operations[strings.ToLower(method)]; exists && operation.OperationId != "" {
+				stubs = append(stubs, stub{operation.OperationId, exportedName(operation.OperationId), method, path, operation.Summary})
+			}
+		}
+	}
+	sort.Slice(stubs, func(i, j int) bool {
+		return stubs[i].operationId < stubs[j].operationId
+	})
+	functions := map[string]string{}
+	for _, item := range stubs {
+		if item.function == "" {
+			return fmt.Errorf("operationId %q can not be converted to a function name", item.operationId)
+		}
+		if existing, exists := functions[item.function]; exists {
+			return fmt.Errorf("operationIds %q and %q both generate %s", existing, item.operationId, item.function)
+		}
+		functions[item.function] = item.operationId
+	}
+
+	source.WriteString("// Handlers operationId 到处理器的映射，传给 HttpRouter.RegisterOpenAPI\n")
+	source.WriteString("var Handlers = routing.HandlerRegistry{\n")
+	for _, item := range stubs {
+		fmt.Fprintf(&source, "%q: %s,\n", item.operationId, item.function)
+	}
+	source.WriteString("}\n")
+
+	for _, item := range stubs {
+		fmt.Fprintf(&source, "\n// %s [%s] %s", item.function, item.method, item.path)
+		if item.summary != "" {
+			fmt.Fprintf(&source, " %s", strings.ReplaceAll(item.summary, "\n", " "))
+		}
+		fmt.Fprintf(&source, "\nfunc %s() any {\n\treturn fmt.Errorf(\"%%w: %%s\", routing.NotImplementedErr, %q)\n}\n", item.function, item.operationId)
+	}
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// exportedName 把 users.show、get-user 这样的名称转换为 UsersShow、GetUser
+func exportedName(name string) string {
+	var result strings.Builder
+	upper := true
+	for _, char := range name {
+		isLetter := unicode.IsLetter(char)
+		if !isLetter && !unicode.IsDigit(char) {
+			upper = true
+			continue
+		}
+		if result.Len() == 0 && !isLetter {
+			result.WriteString("Op")
+		}
+		if upper {
+			char = unicode.ToUpper(char)
+			upper = false
+		}
+		result.WriteRune(char)
+	}
+	return result.String()
+}
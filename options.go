@@ -0,0 +1,302 @@
+package routing
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HttpRouterOption NewHttpRouter 的可选配置
+type HttpRouterOption func(httpRouter *HttpRouter)
+
+// RouterFactory 创建按请求方法划分的路径路由器
+type RouterFactory func() contracts.Router[contracts.Route]
+
+// HostRouterFactory 创建域名路由器，路由器的数据是注册时的域名模板
+type HostRouterFactory func() contracts.Router[string]
+
+// PrefixRouterFactory 创建按请求方法划分的前缀路由器
+type PrefixRouterFactory func() PrefixRouter[contracts.Route]
+
+// WithRouterFactory 使用自定义的路径路由器，例如 routing-matcher 生成的匹配器。
+// 前缀路由和 fallback 路由需要 FindPrefix，仍然使用内置的 Router，参考 WithPrefixRouterFactory
+func WithRouterFactory(factory RouterFactory) HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.routerFactory = factory
+	}
+}
+
+// WithPrefixRouterFactory 使用自定义的前缀路由器，用于前缀路由和 fallback 路由
+func WithPrefixRouterFactory(factory PrefixRouterFactory) HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.prefixRouterFactory = factory
+	}
+}
+
+// WithHostRouterFactory 使用自定义的域名路由器
+func WithHostRouterFactory(factory HostRouterFactory) HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.hostRouterFactory = factory
+	}
+}
+
+// WithStrictSlash 严格区分路径末尾的 /：注册时保留末尾的 /，/users 和 /users/ 是两个不同的路由，URL 生成的路径也保留末尾的 /。
+// 只有内置的 Router 支持，路由器工厂创建了其他实现时 Mount 返回错误
+func WithStrictSlash() HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.strictSlash = true
+	}
+}
+
+// WithCaseInsensitive 路径的静态部分不区分大小写（只处理 ASCII 字母），路由参数保留请求中的原始大小写。
+// 只有内置的 Router 支持，路由器工厂创建了其他实现时 Mount 返回错误
+func WithCaseInsensitive() HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.caseInsensitive = true
+	}
+}
+
+// WithCleanPath 匹配前清理请求路径：合并重复的 /，处理 . 和 .. 路径段，末尾的 / 保持不变
+func WithCleanPath() HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.cleanPath = true
+	}
+}
+
+// WithRedirectTrailingSlash 请求路径末尾的 / 与路由不一致时，Route 返回 *RedirectError 重定向到路由的写法，
+// 例如注册了 /users 时 /users/ 重定向到 /users。不与 WithStrictSlash 一起使用时 /users/ 也不再直接匹配 /users
+func WithRedirectTrailingSlash() HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.redirectTrailingSlash = true
+	}
+}
+
+// WithCanonicalRedirect 请求路径不是规范形式时（重复的 /、. 和 .. 路径段、末尾的 / 与路由不一致），
+// Route 返回 *RedirectError 重定向到规范的 URL，保留查询字符串和路径中的转义，避免同一个页面有多个地址
+func WithCanonicalRedirect() HttpRouterOption {
+	return func(httpRouter *HttpRouter) {
+		httpRouter.canonicalRedirect = true
+	}
+}
+
+// defaultRouter 默认的路径路由器
+func (httpRouter *HttpRouter) defaultRouter() contracts.Router[contracts.Route] {
+	return newRouter[contracts.Route]()
+}
+
+// defaultPrefixRouter 默认的前缀路由器
+func (httpRouter *HttpRouter) defaultPrefixRouter() PrefixRouter[contracts.Route] {
+	return newRouter[contracts.Route]()
+}
+
+// configure 把 WithStrictSlash 和 WithCaseInsensitive 应用到内置的 Router 上，其他实现由 routerOptionErrors 报告
+func (httpRouter *HttpRouter) configure(router any) {
+	if instance, isRouter := router.(*Router[contracts.Route]); isRouter {
+		instance.strictSlash = httpRouter.strictSlash
+		instance.caseInsensitive = httpRouter.caseInsensitive
+	}
+}
+
+// routerOptionErrors 开启 WithStrictSlash 或者 WithCaseInsensitive 时，返回不支持这两个选项的路由器
+func (httpRouter *HttpRouter) routerOptionErrors() []string {
+	if !httpRouter.strictSlash && !httpRouter.caseInsensitive {
+		return nil
+	}
+
+	var routers []any
+	for _, router := range httpRouter.routers {
+		routers = append(routers, router)
+	}
+	for _, hostRouters := range httpRouter.hostRouters {
+		for _, router := range hostRouters {
+			routers = append(routers, router)
+		}
+	}
+	for _, table := range []*prefixTable{httpRouter.prefixes, httpRouter.fallbacks} {
+		for _, router := range table.routers {
+			routers = append(routers, router)
+		}
+		for _, hostRouters := range table.hostRouters {
+			for _, router := range hostRouters {
+				routers = append(routers, router)
+			}
+		}
+	}
+
+	var unsupported []string
+	for _, router := range routers {
+		if _, isRouter := router.(*Router[contracts.Route]); !isRouter {
+			unsupported = append(unsupported, fmt.Sprintf("%T", router))
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("routers [%s] do not support WithStrictSlash or WithCaseInsensitive", strings.Join(unique(unsupported), "|"))}
+}
+
+// optionsSignature 影响路由树的选项，用于路由缓存的校验和
+func (httpRouter *HttpRouter) optionsSignature() string {
+	return fmt.Sprintf("strict=%t,case-insensitive=%t", httpRouter.strictSlash, httpRouter.caseInsensitive)
+}
+
+// requestPath 返回用于匹配的请求路径，开启 WithCleanPath 时先在转义的路径上清理，%2F 不会被当作路径分隔符
+func (httpRouter *HttpRouter) requestPath(u *url.URL) string {
+	if !httpRouter.cleanPath {
+		return u.Path
+	}
+	if cleaned, err := url.PathUnescape(canonicalPath(u.EscapedPath())); err == nil {
+		return cleaned
+	}
+	return u.Path
+}
+
+// matchesSlash 严格区分或者需要重定向末尾的 / 以及规范路径时，请求路径和路由末尾的 / 必须一致
+func (httpRouter *HttpRouter) matchesSlash(route contracts.Route, requestPath string) bool {
+	if !httpRouter.strictSlash && !httpRouter.redirectTrailingSlash && !httpRouter.canonicalRedirect {
+		return true
+	}
+	return hasTrailingSlash(route.GetPath()) == hasTrailingSlash(requestPath)
+}
+
+// trailingSlashRedirect 切换请求路径末尾的 / 之后能匹配路由时返回 *RedirectError
+func (httpRouter *HttpRouter) trailingSlashRedirect(method string, u *url.URL) (contracts.Route, contracts.RouteParams, error) {
+	escapedPath := u.EscapedPath()
+	if httpRouter.cleanPath || httpRouter.canonicalRedirect {
+		escapedPath = canonicalPath(escapedPath)
+	}
+	return httpRouter.redirectTo(method, u, toggleTrailingSlash(escapedPath))
+}
+
+// canonicalPathRedirect 请求路径不是规范形式时，规范路径（或者切换末尾的 / 之后）能匹配路由则返回 *RedirectError
+func (httpRouter *HttpRouter) canonicalPathRedirect(method string, u *url.URL) (contracts.Route, contracts.RouteParams, error) {
+	escapedPath := u.EscapedPath()
+	canonical := canonicalPath(escapedPath)
+	if canonical == escapedPath {
+		return nil, nil, nil
+	}
+	if route, params, err := httpRouter.redirectTo(method, u, canonical); err != nil {
+		return route, params, err
+	}
+	if toggled := toggleTrailingSlash(canonical); toggled != escapedPath {
+		return httpRouter.redirectTo(method, u, toggled)
+	}
+	return nil, nil, nil
+}
+
+// redirectTo 转义的路径 targetPath 能匹配路由时返回重定向到 targetPath 的 *RedirectError，Location 保留原有的转义和查询字符串；
+// GET 和 HEAD 使用 301，其他方法使用 308
+func (httpRouter *HttpRouter) redirectTo(method string, u *url.URL, targetPath string) (contracts.Route, contracts.RouteParams, error) {
+	if targetPath == "" {
+		return nil, nil, nil
+	}
+	unescaped, err := url.PathUnescape(targetPath)
+	if err != nil {
+		return nil, nil, nil
+	}
+	target := *u
+	target.Path, target.RawPath = unescaped, targetPath
+	route, params, err := httpRouter.route(method, &target)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	status := http.StatusPermanentRedirect
+	if method == http.MethodGet || method == http.MethodHead {
+		status = http.StatusMovedPermanently
+	}
+	// 开头的多个 / 合并为一个，否则 //evil.com 会被浏览器当作其他域名
+	location := &url.URL{Path: "/" + strings.TrimLeft(unescaped, "/"), RawPath: "/" + strings.TrimLeft(targetPath, "/"), RawQuery: u.RawQuery}
+	return route, params, &RedirectError{Location: location.String(), Status: status}
+}
+
+// canonicalPath 返回规范的路径，requestPath 是转义的路径（url.URL.EscapedPath），%2F 不会被当作路径分隔符。
+// 按 RFC 3986 第 5.2.4 节移除 . 和 .. 路径段（包括转义的 %2E），末尾的 / 保持不变；
+// 合并重复的 / 超出了 RFC 3986 的规定，RFC 中空路径段是有意义的，这里把 // 视为 /
+func canonicalPath(requestPath string) string {
+	var segments []string
+	parts := strings.Split(requestPath, "/")
+	trailingSlash := false
+	for i, segment := range parts {
+		isLast := i == len(parts)-1
+		switch dotSegment(segment) {
+		case "", ".":
+			trailingSlash = isLast && i > 0
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+			trailingSlash = isLast
+		default:
+			segments = append(segments, segment)
+			trailingSlash = false
+		}
+	}
+
+	if len(segments) == 0 {
+		return "/"
+	}
+	canonical := "/" + strings.Join(segments, "/")
+	if trailingSlash {
+		canonical += "/"
+	}
+	return canonical
+}
+
+// dotSegment 把转义的 . 路径段（%2E、.%2e 等）还原为 . 或者 ..，其他路径段原样返回
+func dotSegment(segment string) string {
+	if len(segment) > 6 || !strings.Contains(segment, "%") {
+		return segment
+	}
+	switch unescaped := strings.ReplaceAll(strings.ReplaceAll(segment, "%2e", "."), "%2E", "."); unescaped {
+	case ".", "..":
+		return unescaped
+	}
+	return segment
+}
+
+// toggleTrailingSlash 添加或者去掉路径末尾的 /，根路径返回空字符串
+func toggleTrailingSlash(requestPath string) string {
+	switch {
+	case requestPath == "/" || requestPath == "":
+		return ""
+	case strings.HasSuffix(requestPath, "/"):
+		return requestPath[:len(requestPath)-1]
+	}
+	return requestPath + "/"
+}
+
+func hasTrailingSlash(value string) bool {
+	return len(value) > 1 && strings.HasSuffix(value, "/")
+}
+
+// lowerStaticSegments 把路径模板的静态部分转换为小写，参数保持不变
+func lowerStaticSegments(template string) string {
+	var result strings.Builder
+	last := 0
+	for _, span := range paramReg.FindAllStringIndex(template, -1) {
+		result.WriteString(lowerASCII(template[last:span[0]]))
+		result.WriteString(template[span[0]:span[1]])
+		last = span[1]
+	}
+	result.WriteString(lowerASCII(template[last:]))
+	return result.String()
+}
+
+// lowerASCII 只转换 ASCII 字母，保证转换前后每个字节的位置不变
+func lowerASCII(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= 'A' && value[i] <= 'Z' {
+			bytes := []byte(value)
+			for j := i; j < len(bytes); j++ {
+				if bytes[j] >= 'A' && bytes[j] <= 'Z' {
+					bytes[j] += 'a' - 'A'
+				}
+			}
+			return string(bytes)
+		}
+	}
+	return value
+}
@@ -0,0 +1,137 @@
+package routing
+
+import (
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"reflect"
+)
+
+// Terminator 实现该接口的中间件在响应发送之后还会执行收尾逻辑
+type Terminator interface {
+	Terminator() contracts.MagicalFunc
+}
+
+type terminableMiddleware struct {
+	contracts.MagicalFunc
+	terminator contracts.MagicalFunc
+}
+
+func (middleware *terminableMiddleware) Terminator() contracts.MagicalFunc {
+	return middleware.terminator
+}
+
+// Terminable 创建一个可终止的中间件，Pipeline.Terminate 时 terminator 会被调用，可以注入请求和响应结果
+func Terminable(middleware any, terminator any) contracts.MagicalFunc {
+	magicalFunc, isMagicalFunc := terminator.(contracts.MagicalFunc)
+	if !isMagicalFunc {
+		magicalFunc = container.NewMagicalFunc(terminator)
+	}
+	return &terminableMiddleware{
+		MagicalFunc: ConvertToMiddlewares(middleware)[0],
+		terminator:  magicalFunc,
+	}
+}
+
+// Pipeline 按顺序执行中间件，最后执行目标处理器。
+// 中间件通过注入的 contracts.Pipe 调用下一层，不调用则直接短路返回自己的结果
+type Pipeline struct {
+	container   contracts.Container
+	middlewares []contracts.MagicalFunc
+}
+
+// NewPipeline 创建中间件管道，container 为 nil 时按参数类型直接注入调用时传入的参数，便于在没有应用实例的情况下测试
+func NewPipeline(container contracts.Container) *Pipeline {
+	return &Pipeline{
+		container:   container,
+		middlewares: make([]contracts.MagicalFunc, 0),
+	}
+}
+
+// NewRoutePipeline 按 全局中间件、组中间件、路由中间件 的顺序组合 route 的中间件管道
+func NewRoutePipeline(container contracts.Container, router contracts.HttpRouter, route contracts.Route) *Pipeline {
+	if httpRouter, isHttpRouter := router.(*HttpRouter); isHttpRouter {
+		return NewPipeline(container).Through(httpRouter.routeStack(route)...)
+	}
+	return NewPipeline(container).
+		Through(router.Middlewares()...).
+		Through(route.Middlewares()...)
+}
+
+// Through 追加中间件，先追加的中间件在外层
+func (pipeline *Pipeline) Through(middlewares ...contracts.MagicalFunc) *Pipeline {
+	pipeline.middlewares = append(pipeline.middlewares, middlewares...)
+	return pipeline
+}
+
+// Middlewares 返回管道中的中间件
+func (pipeline *Pipeline) Middlewares() []contracts.MagicalFunc {
+	return pipeline.middlewares
+}
+
+// Then 让 passable 依次经过中间件，最后交给 destination 处理，args 会注入到每一层的调用中
+func (pipeline *Pipeline) Then(passable any, destination contracts.MagicalFunc, args ...any) any {
+	var next contracts.Pipe = func(passable any) any {
+		return firstResult(pipeline.call(destination, append([]any{passable}, args...)...))
+	}
+	for i := len(pipeline.middlewares) - 1; i >= 0; i-- {
+		middleware, pipe := pipeline.middlewares[i], next
+		next = func(passable any) any {
+			return firstResult(pipeline.call(middleware, append([]any{passable, pipe}, args...)...))
+		}
+	}
+	return next(passable)
+}
+
+// Terminate 响应发送之后按顺序调用可终止中间件的收尾逻辑
+func (pipeline *Pipeline) Terminate(passable any, response any, args ...any) {
+	for _, middleware := range pipeline.middlewares {
+		if terminator, ok := middleware.(Terminator); ok && terminator.Terminator() != nil {
+			pipeline.call(terminator.Terminator(), append([]any{passable, response}, args...)...)
+		}
+	}
+}
+
+func (pipeline *Pipeline) call(fn contracts.MagicalFunc, args ...any) []any {
+	if pipeline.container != nil {
+		return pipeline.container.StaticCall(fn, args...)
+	}
+
+	// 先按类型完全相同绑定，再按可赋值绑定，每个参数只使用一次，
+	// 避免 any 类型的参数抢走本该属于其他参数的值
+	var arguments = fn.Arguments()
+	var in = make([]reflect.Value, len(arguments))
+	var used = make([]bool, len(args))
+	bind := func(matches func(argType, paramType reflect.Type) bool) {
+		for i, paramType := range arguments {
+			if in[i].IsValid() {
+				continue
+			}
+			for j, arg := range args {
+				if !used[j] && arg != nil && matches(reflect.TypeOf(arg), paramType) {
+					in[i], used[j] = reflect.ValueOf(arg), true
+					break
+				}
+			}
+		}
+	}
+	bind(func(argType, paramType reflect.Type) bool { return argType == paramType })
+	bind(reflect.Type.AssignableTo)
+	for i, paramType := range arguments {
+		if !in[i].IsValid() {
+			in[i] = reflect.Zero(paramType)
+		}
+	}
+
+	var results []any
+	for _, result := range fn.Call(in) {
+		results = append(results, result.Interface())
+	}
+	return results
+}
+
+func firstResult(results []any) any {
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0]
+}
@@ -0,0 +1,71 @@
+package routing_test
+
+import (
+	"github.com/goal-web/container"
+	"github.com/goal-web/contracts"
+	"github.com/goal-web/routing"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var trace []string
+	middleware := func(name string) contracts.MagicalFunc {
+		return container.NewMagicalFunc(func(passable string, next contracts.Pipe) any {
+			trace = append(trace, name)
+			return next(passable + name)
+		})
+	}
+	destination := container.NewMagicalFunc(func(passable string) any {
+		return passable
+	})
+
+	pipeline := routing.NewPipeline(nil).
+		Through(middleware("global")).
+		Through(middleware("group"), middleware("route"))
+
+	assert.Equal(t, ">globalgrouproute", pipeline.Then(">", destination))
+	assert.Equal(t, []string{"global", "group", "route"}, trace)
+}
+
+func TestPipelineShortCircuitAndTerminate(t *testing.T) {
+	var terminated any
+	pipeline := routing.NewPipeline(nil).Through(
+		routing.Terminable(func(passable string, next contracts.Pipe) any {
+			return next(passable)
+		}, func(passable string, response int) {
+			terminated = response
+		}),
+		container.NewMagicalFunc(func(passable string) any {
+			return 401
+		}),
+	)
+
+	result := pipeline.Then("request", container.NewMagicalFunc(func() any {
+		return 200
+	}))
+	assert.Equal(t, 401, result)
+
+	pipeline.Terminate("request", result)
+	assert.Equal(t, 401, terminated)
+}
+
+func TestPipelineBindsArgumentsByType(t *testing.T) {
+	var passable, response any
+	pipeline := routing.NewPipeline(nil).Through(
+		routing.Terminable(func(request string, next contracts.Pipe) any {
+			return next(request)
+		}, func(request any, result any) {
+			passable, response = request, result
+		}),
+	)
+
+	result := pipeline.Then("request", container.NewMagicalFunc(func(params contracts.RouteParams, request string) any {
+		return params["id"] + request
+	}), contracts.RouteParams{"id": "1"})
+	assert.Equal(t, "1request", result)
+
+	pipeline.Terminate("request", 200)
+	assert.Equal(t, "request", passable)
+	assert.Equal(t, 200, response)
+}
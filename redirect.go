@@ -0,0 +1,213 @@
+package routing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	InvalidRedirectStatusErr = errors.New("invalid redirect status") // 重定向状态码必须是 300、301、302、303、307 或者 308
+)
+
+// RedirectResponse 重定向路由的处理结果，实现了 http.Handler
+type RedirectResponse struct {
+	Location string
+	Status   int
+}
+
+// ServeHTTP 重定向到 Location，请求的查询字符串会追加到 Location 之后
+func (response RedirectResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, withQuery(response.Location, r.URL.RawQuery), response.Status)
+}
+
+// withQuery 把 query 追加到 location 的查询字符串中，保留 location 的 fragment
+func withQuery(location, query string) string {
+	if query == "" {
+		return location
+	}
+	location, fragment, hasFragment := strings.Cut(location, "#")
+	if strings.Contains(location, "?") {
+		location += "&" + query
+	} else {
+		location += "?" + query
+	}
+	if hasFragment {
+		location += "#" + fragment
+	}
+	return location
+}
+
+// RedirectError Route 要求客户端重定向到 Location，例如 WithRedirectTrailingSlash 时路径末尾的 / 与路由不一致，
+// 或者 WithCanonicalRedirect 时请求路径不是规范形式，Handler 会直接返回重定向响应
+type RedirectError struct {
+	Location string
+	Status   int
+}
+
+func (err *RedirectError) Error() string {
+	return fmt.Sprintf("redirect to %s (%d)", err.Location, err.Status)
+}
+
+// RedirectRule 批量导入的重定向规则
+type RedirectRule struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status"`
+}
+
+// Redirect 注册一个重定向路由，status 为 0 时使用 302，不是重定向状态码时 panic。
+// to 以 / 开头或者是完整的 URL 时作为路径模板，其中的参数用 from 匹配到的参数替换，例如 /blog/{slug} 重定向到 /articles/{slug}；
+// 否则作为路由名，用匹配到的参数生成该路由的路径。请求的查询字符串会保留
+func (httpRouter *HttpRouter) Redirect(from, to string, status int) contracts.Route {
+	status, err := redirectStatus(status)
+	if err != nil {
+		panic(err)
+	}
+
+	return httpRouter.Add(append([]string{}, methodList[:]...), from, func(params contracts.RouteParams) any {
+		location, err := httpRouter.redirectLocation(to, params)
+		if err != nil {
+			return err
+		}
+		return RedirectResponse{Location: location, Status: status}
+	})
+}
+
+// redirectStatus 校验重定向状态码，0 表示 302
+func redirectStatus(status int) (int, error) {
+	switch status {
+	case 0:
+		return http.StatusFound, nil
+	case http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return status, nil
+	}
+	return 0, fmt.Errorf("%w: %d", InvalidRedirectStatusErr, status)
+}
+
+// PermanentRedirect 注册一个 301 重定向路由
+func (httpRouter *HttpRouter) PermanentRedirect(from, to string) contracts.Route {
+	return httpRouter.Redirect(from, to, http.StatusMovedPermanently)
+}
+
+func (httpRouter *HttpRouter) redirectLocation(to string, params contracts.RouteParams) (string, error) {
+	if strings.HasPrefix(to, "/") || strings.Contains(to, "://") {
+		scheme, rest, isAbsolute := strings.Cut(to, "://")
+		if !isAbsolute {
+			return buildPath(to, routeParamsToMap(params))
+		}
+		host, path, _ := strings.Cut(rest, "/")
+		path, err := buildPath("/"+path, routeParamsToMap(params))
+		if err != nil {
+			return "", err
+		}
+		return scheme + "://" + host + path, nil
+	}
+	return httpRouter.URL(to, routeParamsToMap(params))
+}
+
+// LoadRedirects 从 .csv 或者 .json 文件批量导入重定向规则
+func (httpRouter *HttpRouter) LoadRedirects(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = httpRouter.ImportRedirects(file, strings.TrimPrefix(filepath.Ext(filename), ".")); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// ImportRedirects 导入重定向规则。
+// csv 格式每行为 from,to[,status]；json 格式为 RedirectRule 数组，或者 from 到 to 的对象，状态码为 302
+func (httpRouter *HttpRouter) ImportRedirects(reader io.Reader, format string) error {
+	var rules []RedirectRule
+	var err error
+	switch strings.ToLower(format) {
+	case "csv":
+		rules, err = parseCsvRedirects(reader)
+	case "json":
+		rules, err = parseJsonRedirects(reader)
+	default:
+		return fmt.Errorf("unsupported redirect format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if _, err = redirectStatus(rule.Status); err != nil {
+			return fmt.Errorf("%s: %w", rule.From, err)
+		}
+	}
+	for _, rule := range rules {
+		httpRouter.Redirect(rule.From, rule.To, rule.Status)
+	}
+	return nil
+}
+
+func parseCsvRedirects(reader io.Reader) ([]RedirectRule, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.Comment = '#'
+	csvReader.TrimLeadingSpace = true
+
+	var rules []RedirectRule
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("line %d: expected from,to[,status]", line)
+		}
+
+		rule := RedirectRule{From: record[0], To: record[1]}
+		if len(record) == 3 && record[2] != "" {
+			if rule.Status, err = strconv.Atoi(record[2]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid status %q", line, record[2])
+			}
+		}
+		rules = append(rules, rule)
+	}
+}
+
+func parseJsonRedirects(reader io.Reader) ([]RedirectRule, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []RedirectRule
+	if err = json.Unmarshal(content, &rules); err == nil {
+		return rules, nil
+	}
+
+	var redirects map[string]string
+	if mapErr := json.Unmarshal(content, &redirects); mapErr != nil {
+		return nil, err
+	}
+	for from, to := range redirects {
+		rules = append(rules, RedirectRule{From: from, To: to})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].From < rules[j].From
+	})
+	return rules, nil
+}
@@ -0,0 +1,211 @@
+package routing
+
+import (
+	"github.com/goal-web/contracts"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// resourceAction 资源路由的一个动作，例如 index、show
+type resourceAction struct {
+	name     string
+	methods  []string
+	path     string // {prefix} 和 {param} 会被替换为实际的路径前缀和参数
+	member   bool   // 是否作用于单个资源，浅层嵌套时成员动作不带父级资源
+	singular bool   // 单例资源也有该动作
+}
+
+var resourceActions = []resourceAction{
+	{name: "index", methods: []string{http.MethodGet}, path: "{prefix}"},
+	{name: "create", methods: []string{http.MethodGet}, path: "{prefix}/create"},
+	{name: "store", methods: []string{http.MethodPost}, path: "{prefix}"},
+	{name: "show", methods: []string{http.MethodGet}, path: "{prefix}/{param}", member: true, singular: true},
+	{name: "edit", methods: []string{http.MethodGet}, path: "{prefix}/{param}/edit", member: true, singular: true},
+	{name: "update", methods: []string{http.MethodPut, http.MethodPatch}, path: "{prefix}/{param}", member: true, singular: true},
+	{name: "destroy", methods: []string{http.MethodDelete}, path: "{prefix}/{param}", member: true},
+}
+
+// ResourceOption 资源路由选项
+type ResourceOption func(options *resourceOptions)
+
+type resourceOptions struct {
+	only        []string
+	except      []string
+	shallow     bool
+	singleton   bool
+	parameters  map[string]string
+	middlewares []any
+}
+
+// Only 只注册指定的动作
+func Only(actions ...string) ResourceOption {
+	return func(options *resourceOptions) {
+		options.only = append(options.only, actions...)
+	}
+}
+
+// Except 不注册指定的动作
+func Except(actions ...string) ResourceOption {
+	return func(options *resourceOptions) {
+		options.except = append(options.except, actions...)
+	}
+}
+
+// Shallow 浅层嵌套，show、edit、update、destroy 不带父级资源，例如 /comments/{comment}，路由名为 comments.show
+func Shallow() ResourceOption {
+	return func(options *resourceOptions) {
+		options.shallow = true
+	}
+}
+
+// Singleton 单例资源，只有 show、edit、update 并且不需要资源参数，例如 /profile、/profile/edit
+func Singleton() ResourceOption {
+	return func(options *resourceOptions) {
+		options.singleton = true
+	}
+}
+
+// Parameter 指定资源的参数名，默认为资源名的单数形式，例如 photos 的参数为 photo
+func Parameter(resource, param string) ResourceOption {
+	return func(options *resourceOptions) {
+		options.parameters[resource] = param
+	}
+}
+
+// ResourceMiddlewares 为资源的所有路由添加中间件
+func ResourceMiddlewares(middlewares ...any) ResourceOption {
+	return func(options *resourceOptions) {
+		options.middlewares = append(options.middlewares, middlewares...)
+	}
+}
+
+// resourceRoute 解析后待注册的资源路由
+type resourceRoute struct {
+	methods []string
+	path    string
+	name    string
+	handler any
+}
+
+// resourceRoutes 生成资源路由，name 为 photos.comments 时生成 /photos/{photo}/comments/{comment}，
+// controller 上与动作同名的导出方法（Index、Show 等）作为处理器，没有实现的动作不注册
+func resourceRoutes(name string, controller any, options []ResourceOption) ([]resourceRoute, []any) {
+	var opts = &resourceOptions{parameters: map[string]string{}}
+	for _, option := range options {
+		option(opts)
+	}
+
+	resources := strings.Split(name, ".")
+	resource := resources[len(resources)-1]
+
+	var parentPrefix string
+	for _, parent := range resources[:len(resources)-1] {
+		parentPrefix += "/" + parent + "/{" + opts.param(parent) + "}"
+	}
+
+	var routes []resourceRoute
+	var controllerValue = reflect.ValueOf(controller)
+	for _, action := range resourceActions {
+		if !opts.includes(action) {
+			continue
+		}
+		method := controllerValue.MethodByName(strings.ToUpper(action.name[:1]) + action.name[1:])
+		if !method.IsValid() {
+			continue
+		}
+
+		prefix, routeName := parentPrefix+"/"+resource, name
+		if opts.shallow && action.member {
+			prefix, routeName = "/"+resource, resource
+		}
+
+		path := strings.Replace(action.path, "{prefix}", prefix, 1)
+		if opts.singleton {
+			path = strings.Replace(path, "/{param}", "", 1)
+		} else {
+			path = strings.Replace(path, "{param}", "{"+opts.param(resource)+"}", 1)
+		}
+
+		routes = append(routes, resourceRoute{
+			methods: action.methods,
+			path:    path,
+			name:    routeName + "." + action.name,
+			handler: method.Interface(),
+		})
+	}
+
+	return routes, opts.middlewares
+}
+
+func (options *resourceOptions) includes(action resourceAction) bool {
+	if options.singleton && !action.singular {
+		return false
+	}
+	if len(options.only) > 0 && !containsString(options.only, action.name) {
+		return false
+	}
+	return !containsString(options.except, action.name)
+}
+
+func (options *resourceOptions) param(resource string) string {
+	if param, exists := options.parameters[resource]; exists {
+		return param
+	}
+	return singular(resource)
+}
+
+// singular 把资源名转换为单数形式，只处理常见的英文复数规则，特殊情况请使用 Parameter 指定
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource 注册资源路由：index、create、store、show、edit、update、destroy，路由名为 photos.show 这样的格式
+func (httpRouter *HttpRouter) Resource(name string, controller any, options ...ResourceOption) []contracts.Route {
+	routes, middlewares := resourceRoutes(name, controller, options)
+	var results []contracts.Route
+	for _, route := range routes {
+		results = append(results, httpRouter.Add(route.methods, route.path, route.handler, middlewares...).Name(route.name))
+	}
+	return results
+}
+
+// ApiResource 注册不包含 create 和 edit 的资源路由
+func (httpRouter *HttpRouter) ApiResource(name string, controller any, options ...ResourceOption) []contracts.Route {
+	return httpRouter.Resource(name, controller, append(options, Except("create", "edit"))...)
+}
+
+// Resource 在组内注册资源路由，参考 HttpRouter.Resource
+func (group *Group) Resource(name string, controller any, options ...ResourceOption) contracts.RouteGroup {
+	routes, middlewares := resourceRoutes(name, controller, options)
+	for _, route := range routes {
+		group.Add(route.methods, route.path, route.handler, middlewares...)
+		group.routes[len(group.routes)-1].Name(route.name)
+	}
+	return group
+}
+
+// ApiResource 在组内注册不包含 create 和 edit 的资源路由
+func (group *Group) ApiResource(name string, controller any, options ...ResourceOption) contracts.RouteGroup {
+	return group.Resource(name, controller, append(options, Except("create", "edit"))...)
+}
@@ -11,6 +11,20 @@ type Route struct {
 	handler     contracts.MagicalFunc
 	name        string
 	host        string
+
+	// 需要从继承的中间件中排除的中间件，Mount 时生效
+	excludedMiddlewares []any
+	group               *Group
+	// middlewares 的前 inherited 个中间件继承自组，其余为路由自身声明的中间件
+	inherited int
+	// Mount 时计算的最终生效的中间件，包含排除后的全局中间件，请求时直接使用
+	stack []contracts.MagicalFunc
+
+	// 路由元数据
+	meta map[string]any
+
+	// 路由的来源，例如路由配置文件中的位置，Mount 出现冲突时用于定位
+	origin string
 }
 
 func NewRoute(method []string, path string, middlewares []contracts.MagicalFunc, handler contracts.MagicalFunc) contracts.Route {
@@ -22,6 +36,18 @@ func NewRoute(method []string, path string, middlewares []contracts.MagicalFunc,
 	}
 }
 
+// newRoute 创建不属于任何组的路由，中间件参数中的 Without 标记作为路由排除的中间件
+func newRoute(method []string, path string, handler any, middlewares []any) *Route {
+	middlewares, excluded := splitExclusions(middlewares)
+	return &Route{
+		method:              method,
+		path:                path,
+		middlewares:         ConvertToMiddlewares(middlewares...),
+		handler:             toHandler(handler),
+		excludedMiddlewares: excluded,
+	}
+}
+
 func (route *Route) Name(name string) contracts.Route {
 	route.name = name
 	return route
@@ -32,8 +58,33 @@ func (route *Route) Host(host string) contracts.Route {
 	return route
 }
 
+// WithoutMiddleware 从路由继承的全局中间件和组中间件中排除指定的中间件，可以是别名、中间件实例或者函数
+func (route *Route) WithoutMiddleware(middlewares ...any) contracts.Route {
+	route.excludedMiddlewares = append(route.excludedMiddlewares, checkExcluded(middlewares)...)
+	return route
+}
+
+// excluded 返回路由自身以及所在组逐级声明排除的中间件
+func (route *Route) excluded() []any {
+	excluded := route.excludedMiddlewares
+	for group := route.group; group != nil; group = group.parent {
+		excluded = append(excluded[:len(excluded):len(excluded)], group.excludedMiddlewares...)
+	}
+	return excluded
+}
+
+// splitMiddlewares 返回继承自组的中间件和路由自身声明的中间件
+func (route *Route) splitMiddlewares() ([]contracts.MagicalFunc, []contracts.MagicalFunc) {
+	inherited := route.inherited
+	if inherited > len(route.middlewares) {
+		inherited = len(route.middlewares)
+	}
+	return route.middlewares[:inherited:inherited], route.middlewares[inherited:]
+}
+
+// Middlewares 返回路由中间件的副本，修改返回值不会影响路由
 func (route *Route) Middlewares() []contracts.MagicalFunc {
-	return route.middlewares
+	return mergeMiddlewares(route.middlewares)
 }
 
 func (route *Route) Method() []string {
@@ -44,7 +95,11 @@ func (route *Route) GetPath() string {
 	return route.path
 }
 
+// GetHost 返回路由的域名，没有设置时继承所在组的域名
 func (route *Route) GetHost() string {
+	if route.host == "" && route.group != nil {
+		return route.group.GetHost()
+	}
 	return route.host
 }
 
@@ -0,0 +1,347 @@
+package routing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/goal-web/contracts"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// RouteConfig 路由配置文件中的一条路由
+type RouteConfig struct {
+	Method      string   `json:"method" yaml:"method" toml:"method"`
+	Methods     []string `json:"methods" yaml:"methods" toml:"methods"`
+	Path        string   `json:"path" yaml:"path" toml:"path"`
+	Name        string   `json:"name" yaml:"name" toml:"name"`
+	Host        string   `json:"host" yaml:"host" toml:"host"`
+	Middlewares []string `json:"middlewares" yaml:"middlewares" toml:"middlewares"` // 中间件别名或者中间件组
+	Handler     string   `json:"handler" yaml:"handler" toml:"handler"`             // 处理器的键，在处理器注册表或者容器中查找
+	Disabled    bool     `json:"disabled" yaml:"disabled" toml:"disabled"`
+
+	// File 和 Line 记录路由在配置文件中的位置，用于错误提示
+	File string `json:"-" yaml:"-" toml:"-"`
+	Line int    `json:"-" yaml:"-" toml:"-"`
+}
+
+func (config RouteConfig) position() string {
+	return fmt.Sprintf("%s:%d", config.File, config.Line)
+}
+
+func (config RouteConfig) methods() []string {
+	methods := append([]string{}, config.Methods...)
+	if config.Method != "" {
+		methods = append(methods, config.Method)
+	}
+	for i, method := range methods {
+		methods[i] = strings.ToUpper(method)
+	}
+	return methods
+}
+
+// LoadRouteConfig 读取路由配置文件，根据扩展名支持 .json、.yaml、.yml 和 .toml，路由定义在顶层的 routes 列表中
+func LoadRouteConfig(filename string) ([]RouteConfig, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRouteConfig(content, filename)
+}
+
+// ParseRouteConfig 解析路由配置，filename 用于判断格式以及错误提示
+func ParseRouteConfig(content []byte, filename string) ([]RouteConfig, error) {
+	var routes []RouteConfig
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		routes, err = parseJsonRouteConfig(content)
+	case ".yaml", ".yml":
+		routes, err = parseYamlRouteConfig(content)
+	case ".toml":
+		routes, err = parseTomlRouteConfig(content)
+	default:
+		return nil, fmt.Errorf("%s: unsupported route config format", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	for i := range routes {
+		routes[i].File = filename
+	}
+	return routes, nil
+}
+
+func parseJsonRouteConfig(content []byte) ([]RouteConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("route config must be an object")
+	}
+
+	var routes []RouteConfig
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key != "routes" {
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, fmt.Errorf("line %d: routes must be an array", lineAt(content, int(decoder.InputOffset())))
+		}
+		for decoder.More() {
+			line := lineAt(content, nextValueOffset(content, int(decoder.InputOffset())))
+			var route RouteConfig
+			if err = decoder.Decode(&route); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			route.Line = line
+			routes = append(routes, route)
+		}
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return routes, nil
+}
+
+func parseYamlRouteConfig(content []byte) ([]RouteConfig, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: route config must be a mapping", root.Line)
+	}
+
+	var routes []RouteConfig
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "routes" {
+			continue
+		}
+		list := root.Content[i+1]
+		if list.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: routes must be a sequence", list.Line)
+		}
+		for _, item := range list.Content {
+			var route RouteConfig
+			if err := item.Decode(&route); err != nil {
+				return nil, fmt.Errorf("line %d: %w", item.Line, err)
+			}
+			route.Line = item.Line
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+func parseTomlRouteConfig(content []byte) ([]RouteConfig, error) {
+	var config struct {
+		Routes []RouteConfig `toml:"routes"`
+	}
+	if _, err := toml.Decode(string(content), &config); err != nil {
+		return nil, err
+	}
+
+	// toml 解析结果不带位置信息，按顺序对应每个 [[routes]] 表头所在的行
+	var lines []int
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "[[routes]]") {
+			lines = append(lines, line)
+		}
+	}
+	for i := range config.Routes {
+		if i < len(lines) {
+			config.Routes[i].Line = lines[i]
+		}
+	}
+	return config.Routes, nil
+}
+
+// nextValueOffset 跳过空白和逗号，返回下一个值开始的位置
+func nextValueOffset(content []byte, offset int) int {
+	for offset < len(content) && strings.ContainsRune(" \t\r\n,", rune(content[offset])) {
+		offset++
+	}
+	return offset
+}
+
+func lineAt(content []byte, offset int) int {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// RouteConfigError 路由配置校验失败，每一项都带有文件和行号
+type RouteConfigError struct {
+	Errors []string
+}
+
+func (err *RouteConfigError) Error() string {
+	return "invalid route config:\n" + strings.Join(err.Errors, "\n")
+}
+
+// registerRouteConfigs 校验并注册路由，resolve 根据处理器的键返回处理器，hasMiddleware 为 nil 时不校验中间件别名
+func registerRouteConfigs(routes []RouteConfig, resolve func(key string) any, hasMiddleware func(name string) bool, add func(methods []string, path string, handler any, middlewares ...any) contracts.Route) error {
+	var failures []string
+	type pending struct {
+		config  RouteConfig
+		handler any
+	}
+	var valid []pending
+
+	for _, route := range routes {
+		if route.Disabled {
+			continue
+		}
+
+		var routeFailures []string
+		methods := route.methods()
+		if len(methods) == 0 {
+			routeFailures = append(routeFailures, "method is required")
+		}
+		for _, method := range methods {
+			if !containsString(methodList[:], method) {
+				routeFailures = append(routeFailures, fmt.Sprintf("unknown method %q", method))
+			}
+		}
+		if !strings.HasPrefix(route.Path, "/") {
+			routeFailures = append(routeFailures, fmt.Sprintf("path %q must start with /", route.Path))
+		}
+
+		var handler any
+		if route.Handler == "" {
+			routeFailures = append(routeFailures, "handler is required")
+		} else if handler = resolve(route.Handler); handler == nil {
+			routeFailures = append(routeFailures, fmt.Sprintf("unknown handler %q", route.Handler))
+		} else if !isFunc(handler) {
+			routeFailures = append(routeFailures, fmt.Sprintf("handler %q is not a function", route.Handler))
+		}
+
+		if hasMiddleware != nil {
+			for _, middleware := range route.Middlewares {
+				if !hasMiddleware(middleware) {
+					routeFailures = append(routeFailures, fmt.Sprintf("unknown middleware %q", middleware))
+				}
+			}
+		}
+
+		for _, failure := range routeFailures {
+			failures = append(failures, fmt.Sprintf("%s: %s", route.position(), failure))
+		}
+		if len(routeFailures) == 0 {
+			valid = append(valid, pending{config: route, handler: handler})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &RouteConfigError{Errors: failures}
+	}
+
+	for _, item := range valid {
+		var middlewares []any
+		for _, middleware := range item.config.Middlewares {
+			middlewares = append(middlewares, middleware)
+		}
+		route := add(item.config.methods(), item.config.Path, item.handler, middlewares...)
+		if original, isRoute := route.(*Route); isRoute {
+			original.origin = item.config.position()
+		}
+		if item.config.Name != "" {
+			route.Name(item.config.Name)
+		}
+		if item.config.Host != "" {
+			route.Host(item.config.Host)
+		}
+	}
+	return nil
+}
+
+// LoadRoutes 读取路由配置文件并注册路由，处理器的键先在 handlers 中查找，找不到时从容器中获取。
+// 配置有误时不会注册任何路由，返回的 *RouteConfigError 中每一项都带有文件和行号；
+// 引用的中间件别名和中间件组需要在加载之前注册，Mount 时出现的冲突也会标明路由所在的文件和行号
+func (httpRouter *HttpRouter) LoadRoutes(filename string, handlers HandlerRegistry) error {
+	routes, err := LoadRouteConfig(filename)
+	if err != nil {
+		return err
+	}
+	return httpRouter.RegisterRouteConfigs(routes, handlers)
+}
+
+// RegisterRouteConfigs 注册已经解析好的路由配置，参考 LoadRoutes
+func (httpRouter *HttpRouter) RegisterRouteConfigs(routes []RouteConfig, handlers HandlerRegistry) error {
+	return registerRouteConfigs(routes, handlerResolver(handlers, httpRouter.app), httpRouter.hasMiddleware, func(methods []string, path string, handler any, middlewares ...any) contracts.Route {
+		return httpRouter.Add(methods, path, handler, middlewares...)
+	})
+}
+
+// hasMiddleware 中间件引用（可以带参数）是否为已经注册的中间件别名或者中间件组
+func (httpRouter *HttpRouter) hasMiddleware(reference string) bool {
+	name, _, _ := strings.Cut(reference, ":")
+	_, isAlias := httpRouter.middlewareAliases[name]
+	_, isGroup := httpRouter.middlewareGroups[name]
+	return isAlias || isGroup
+}
+
+// handlerResolver 先在 handlers 中查找处理器，找不到时从容器中获取
+func handlerResolver(handlers HandlerRegistry, app contracts.Application) func(key string) any {
+	return func(key string) any {
+		if handler, exists := handlers[key]; exists {
+			return handler
+		}
+		if app != nil {
+			return app.Get(key)
+		}
+		return nil
+	}
+}
+
+// isFunc 处理器只能是函数或者 contracts.MagicalFunc
+func isFunc(handler any) bool {
+	if _, isMagicalFunc := handler.(contracts.MagicalFunc); isMagicalFunc {
+		return true
+	}
+	return reflect.ValueOf(handler).Kind() == reflect.Func
+}
+
+// LoadRoutes 读取路由配置文件并注册到组内，处理器的键先在 handlers 中查找，找不到时从 HttpRouter 的容器中获取
+func (group *Group) LoadRoutes(filename string, handlers HandlerRegistry) error {
+	routes, err := LoadRouteConfig(filename)
+	if err != nil {
+		return err
+	}
+	return group.RegisterRouteConfigs(routes, handlers)
+}
+
+// RegisterRouteConfigs 把已经解析好的路由配置注册到组内，参考 Group.LoadRoutes，
+// 不是通过 HttpRouter 创建的组没有中间件注册表，中间件别名在 Mount 时解析
+func (group *Group) RegisterRouteConfigs(routes []RouteConfig, handlers HandlerRegistry) error {
+	var hasMiddleware func(name string) bool
+	if router := group.httpRouter(); router != nil {
+		hasMiddleware = router.hasMiddleware
+	}
+	return registerRouteConfigs(routes, handlerResolver(handlers, group.application()), hasMiddleware, func(methods []string, path string, handler any, middlewares ...any) contracts.Route {
+		group.Add(methods, path, handler, middlewares...)
+		return group.routes[len(group.routes)-1]
+	})
+}
@@ -1,204 +1,192 @@
 package routing_test
 
 import (
-	"fmt"
-	"github.com/goal-web/contracts"
 	"github.com/goal-web/routing"
+	"github.com/goal-web/routing/routingtest"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
 )
 
+//go:generate go run ./cmd/routing-matcher -config testdata/conformance_routes.yaml -package routing_test -name ConformanceMatcher -o conformance_matcher_test.go
+
 func TestRouter(t *testing.T) {
-	router := routing.NewRouter[*RouterTest]()
-
-	for route, tests := range routes {
-		signature, err := router.Add(route, tests)
-		assert.NoError(t, err, signature, route)
-		for _, errRoute := range tests.errRoutes {
-			signature, err = router.Add(errRoute, tests)
-			assert.Error(t, err, signature, route)
+	router := routing.NewRouter[*routingtest.RouterCase]()
+	cases := routingtest.RouterCases()
+
+	for i := range cases {
+		item := &cases[i]
+		signature, err := router.Add(item.Route, item)
+		assert.NoError(t, err, signature, item.Route)
+		for _, errRoute := range item.ErrRoutes {
+			signature, err = router.Add(errRoute, item)
+			assert.Error(t, err, signature, item.Route)
 		}
 	}
 
-	for route, test := range routes {
-		for path, params := range test.successfulPaths {
-			_, results, err := router.Find(path)
-			if err != nil {
-				fmt.Println(route, path, err.Error())
-			} else {
+	for _, item := range cases {
+		for path, params := range item.Found {
+			data, results, err := router.Find(path)
+			if assert.NoError(t, err, item.Route, path) {
+				assert.Equal(t, item.Route, data.Route, path)
 				for key, value := range params {
-					if results[key] != value {
-						assert.True(t, results[key] == value)
-					}
+					assert.Equal(t, value, results[key], item.Route, path, key)
 				}
 			}
-			assert.NoError(t, err, err)
-
 		}
-		for _, path := range test.notFoundPaths {
+		for _, path := range item.NotFound {
 			_, _, err := router.Find(path)
-			if err == nil {
-				fmt.Println(route, path)
-			}
-			assert.Error(t, err, err)
+			assert.Error(t, err, item.Route, path)
 		}
 	}
-	fmt.Println(router)
 }
 
-var routes = map[string]*RouterTest{
-	"/books/{name}_description": {
-		successfulPaths: map[string]contracts.Fields{
-			"/books/docker_description": {"name": "docker"},
-			"/books/k8s_description":    {"name": "k8s"},
-		},
-		notFoundPaths: []string{
-			"/books/docker/description",
-			"/books/docker/_description",
-		},
-	},
-	"/books1/{name?}_description": {
-		successfulPaths: map[string]contracts.Fields{
-			"/books1/docker_description": {"name": "docker"},
-			"/books1/k8s_description":    {"name": "k8s"},
-			"/books1/_description":       {"name": ""},
-		},
-		notFoundPaths: []string{
-			"/books1/docker/description",
-			"/books1/docker/_description",
-		},
-	},
-	"/articles/first_{type}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/articles/first_docker": {"type": "docker"},
-			"/articles/first_k8s":    {"type": "k8s"},
-		},
-		notFoundPaths: []string{
-			"/articles/first_/description",
-			"/articles/first/_description",
-		},
-	},
-	"/articles1/first_{type?}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/articles1/first_docker": {"type": "docker"},
-			"/articles/first_k8s":     {"type": "k8s"},
-			"/articles/first_":        {"type": ""},
-		},
-		notFoundPaths: []string{
-			"/articles1/first_/description",
-			"/articles1/first/_description",
-		},
-	},
-	"/users1/{name}/{level?}": {
-		errRoutes: []string{"/users1/{xx}/{xxx:.*}"},
-		successfulPaths: map[string]contracts.Fields{
-			"/users1/xxx":       {"name": "xxx", "level": ""},
-			"/users1/xx/sadad":  {"name": "xx", "level": "sadad"},
-			"/users1/xx/sadad/": {"name": "xx", "level": "sadad"},
-			"/users1/dd/":       {"name": "dd", "level": ""},
-		},
-		notFoundPaths: []string{
-			"/users1/xxx/da/1",
-		},
-	},
-	"/users": {
-		successfulPaths: map[string]contracts.Fields{
-			"/users":  {},
-			"/users/": {},
-		},
-		notFoundPaths: []string{
-			"/usersx",
-		},
-	},
-	"/users/{name}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/users/xxx": {"name": "xxx"},
-		},
-		notFoundPaths: []string{
-			"/users/xxx/da",
-		},
-	},
-	"/homepage/{name?}/hosts": {
-		errRoutes: []string{"/homepage/{xx}/hosts"},
-		successfulPaths: map[string]contracts.Fields{
-			"/homepage/xxx/hosts": {"name": "xxx"},
-			"/homepage/hosts":     {"name": ""},
-		},
-		notFoundPaths: []string{
-			"/homepage/xxx/hosts1",
-			"/homepage/hosts1",
-		},
-	},
-	"/homepage/{name?}/news": {
-		successfulPaths: map[string]contracts.Fields{
-			"/homepage/xxx/news": {"name": "xxx"},
-			"/homepage/news":     {"name": ""},
-		},
-		notFoundPaths: []string{
-			"/homepage/xxx/news1",
-			"/homepage/news1",
-		},
-	},
-	"/category/{category:[0-9]+}/archive/{archive}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/category/1/archive/any": {"category": "1", "archive": "any"},
-		},
-		notFoundPaths: []string{
-			"/category/any/archive/any",
-		},
-	},
-	"/category/{category}/posts/{archive:[0-9]+}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/category/any/posts/1": {"category": "any", "archive": "1"},
-		},
-		notFoundPaths: []string{
-			"/category/any/posts/any",
-		},
-	},
-	"/posts/{id}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/posts/first": {"id": "first"},
-		},
-		notFoundPaths: []string{
-			"/posts/first/xxx",
-		},
-		errRoutes: []string{
-			"/posts/{name}",
-		},
-	},
-	"/archives/{id:[0-9]+?}": {
-		successfulPaths: map[string]contracts.Fields{
-			"/archives/1": {"id": "1"},
-			"/archives/":  {"id": ""},
-			"/archives":   {"id": ""},
-		},
-		notFoundPaths: []string{
-			"/archives/any",
-		},
-	},
+func TestRouterConformance(t *testing.T) {
+	routingtest.RunRouterConformance(t, routing.NewRouter[any])
 }
 
-type RouterTest struct {
-	successfulPaths map[string]contracts.Fields
-	notFoundPaths   []string
-	errRoutes       []string
-}
+func TestGeneratedMatcher(t *testing.T) {
+	routingtest.RunRouterConformance(t, NewConformanceMatcher[any])
 
-func BenchmarkName(b *testing.B) {
+	_, err := NewConformanceMatcher[any]().Add("/unknown/{id}", nil)
+	assert.ErrorIs(t, err, routing.RouteNotCompiledErr)
+
+	configs, err := routing.LoadRouteConfig("testdata/conformance_routes.yaml")
+	assert.NoError(t, err)
+	patterns := routing.MatcherRoutesFromConfig(configs, "")
+	httpRouter := routing.NewHttpRouter(nil).(*routing.HttpRouter)
+	assert.NoError(t, httpRouter.LoadRoutes("testdata/conformance_routes.yaml", routing.HandlerRegistry{"conformance": func() string { return "conformance" }}))
+	assert.NoError(t, httpRouter.Mount())
+	for _, item := range routingtest.RouterCases() {
+		assert.Contains(t, patterns, item.Route)
+		for _, errRoute := range item.ErrRoutes {
+			assert.Contains(t, patterns, errRoute)
+		}
+	}
+
+	var source strings.Builder
+	assert.NoError(t, routing.GenerateMatcher(&source, routing.MatcherOptions{
+		Package: "routing_test", Name: "ConformanceMatcher", Routes: patterns,
+	}))
+	generated, err := os.ReadFile("conformance_matcher_test.go")
+	assert.NoError(t, err)
+	assert.Equal(t, source.String(), string(generated), "conformance_matcher_test.go is out of date, run go generate")
 
-	router := routing.NewRouter[*RouterTest]()
+	source.Reset()
+	assert.NoError(t, routing.GenerateMatcher(&source, routing.MatcherOptions{
+		Package: "api", Name: "ApiMatcher", Routes: []string{"/users/{name:^[a-z_]+$}", "/posts/{slug:[a-z]+-[0-9]+}"},
+	}))
+	assert.Contains(t, source.String(), "if c := value[i]; !((c >= 'a' && c <= 'z') || c == '_') {")
+	assert.Contains(t, source.String(), `var apiMatcherReg0 = regexp.MustCompile("[a-z]+-[0-9]+")`)
+}
 
-	for route, tests := range routes {
-		router.Add(route, tests)
+func BenchmarkName(b *testing.B) {
+	router := routing.NewRouter[string]()
+	cases := routingtest.RouterCases()
+	for _, item := range cases {
+		_, _ = router.Add(item.Route, item.Route)
 	}
 
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		for _, test := range routes {
-			for _, tests := range test.successfulPaths {
-				for path := range tests {
-					_, _, _ = router.Find(path)
-				}
+		for _, item := range cases {
+			for path := range item.Found {
+				_, _, _ = router.Find(path)
 			}
 		}
 	}
 }
+
+func TestRouterFindAll(t *testing.T) {
+	router := routing.NewRouter[string]().(*routing.Router[string])
+	_, _ = router.Add("/files/{name}", "name")
+	_, _ = router.Add("/files/{id:[0-9]+}", "id")
+	_, _ = router.Add("/files/latest", "latest")
+
+	matches, err := router.FindAll("/files/1")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+	for _, match := range matches {
+		assert.Equal(t, "1", match.Params[match.Data])
+	}
+
+	matches, err = router.FindAll("/files/latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "latest", matches[0].Data)
+	assert.Nil(t, matches[0].Params)
+	assert.Len(t, matches, 2)
+
+	_, err = router.FindAll("/files/1/2")
+	assert.ErrorIs(t, err, routing.NotFoundErr)
+}
+
+func TestRouterExplain(t *testing.T) {
+	router := routing.NewRouter[string]().(*routing.Router[string])
+	_, _ = router.Add("/category/{category:[0-9]+}/archive", "archive")
+
+	steps := router.Explain("/category/any/archive")
+	var rejected bool
+	for _, step := range steps {
+		if step.Param == "category" && step.Value == "any" && !step.Accepted {
+			rejected = true
+		}
+	}
+	assert.True(t, rejected, steps)
+
+	steps = router.Explain("/category/1/archive")
+	assert.True(t, steps[len(steps)-1].Accepted, steps)
+}
+
+func TestRouterFindPrefix(t *testing.T) {
+	router := routing.NewRouter[string]().(*routing.Router[string])
+	_, _ = router.Add("/admin", "admin")
+	_, _ = router.Add("/tenants/{id}", "tenant")
+
+	result, _, remainder, err := router.FindPrefix("/admin/anything/deep")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", result)
+	assert.Equal(t, "/anything/deep", remainder)
+
+	_, _, remainder, err = router.FindPrefix("/admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "/", remainder)
+
+	result, params, remainder, err := router.FindPrefix("/tenants/1/settings")
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant", result)
+	assert.Equal(t, "1", params["id"])
+	assert.Equal(t, "/settings", remainder)
+
+	_, _, _, err = router.FindPrefix("/users/1")
+	assert.ErrorIs(t, err, routing.NotFoundErr)
+}
+
+func TestRouterSharedParamNode(t *testing.T) {
+	routes := []string{"/photos/{photo}", "/photos/{photo}/edit", "/homepage/{name?}/hosts", "/homepage/{name?}/news"}
+	reversed := make([]string, 0, len(routes))
+	for i := len(routes) - 1; i >= 0; i-- {
+		reversed = append(reversed, routes[i])
+	}
+
+	for _, order := range [][]string{routes, reversed} {
+		router := routing.NewRouter[string]()
+		for _, route := range order {
+			_, err := router.Add(route, route)
+			assert.NoError(t, err)
+		}
+
+		for path, expected := range map[string]string{
+			"/photos/1":          "/photos/{photo}",
+			"/photos/1/edit":     "/photos/{photo}/edit",
+			"/homepage/xx/hosts": "/homepage/{name?}/hosts",
+			"/homepage/news":     "/homepage/{name?}/news",
+		} {
+			result, _, err := router.Find(path)
+			assert.NoError(t, err, path)
+			assert.Equal(t, expected, result, path)
+		}
+		_, _, err := router.Find("/photos/1/delete")
+		assert.ErrorIs(t, err, routing.NotFoundErr)
+	}
+}
@@ -2,8 +2,10 @@ package routing
 
 import (
 	"errors"
+	"fmt"
 	"github.com/goal-web/contracts"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -19,9 +21,25 @@ type Router[T any] struct {
 	paths        map[string]T
 	paramsRoutes map[string][]*RouterNode[T]
 	signatures   map[string]struct{}
+	prefixes     []string // paramsRoutes 的前缀，按 sortedPrefixes 的顺序在 Add 时维护
+
+	// strictSlash 为 true 时查找前不去掉路径末尾的 /
+	strictSlash bool
+	// caseInsensitive 为 true 时添加路由时静态部分转换为小写，查找时静态部分忽略 ASCII 字母的大小写，参数值保持原样
+	caseInsensitive bool
+}
+
+// PrefixRouter 支持最长前缀查找的路由器
+type PrefixRouter[T any] interface {
+	contracts.Router[T]
+	FindPrefix(path string) (T, contracts.RouteParams, string, error)
 }
 
 func NewRouter[T any]() contracts.Router[T] {
+	return newRouter[T]()
+}
+
+func newRouter[T any]() *Router[T] {
 	return &Router[T]{
 		paths:        map[string]T{},
 		paramsRoutes: map[string][]*RouterNode[T]{},
@@ -34,78 +52,279 @@ func (router *Router[T]) IsEmpty() bool {
 }
 
 func (router *Router[T]) Find(path string) (T, contracts.RouteParams, error) {
-	if strings.HasSuffix(path, "/") && path != "/" {
-		path = path[:len(path)-1]
-	}
-	result, ok := router.paths[path]
+	path = router.trimPath(path)
+	result, ok := router.static(path)
 	if ok {
 		return result, nil, nil
 	}
 
-	var params = make(contracts.RouteParams)
-	tmpResult, err := router.find(path, router.paramsRoutes, params)
-	return tmpResult, params, err
+	var found bool
+	var m = &matcher[T]{
+		fold:   router.caseInsensitive,
+		params: make(contracts.RouteParams),
+		hit: func(data T, _ contracts.RouteParams) bool {
+			result = data
+			found = true
+			return true
+		},
+	}
+	m.walk(path, router.paramsRoutes, router.prefixes, 0)
+	if !found {
+		return result, m.params, NotFoundErr
+	}
+	return result, m.params, nil
+}
+
+// FindPrefix 查找拥有 path 的最长已注册前缀，remainder 为前缀之后剩余的路径，总是以 / 开头
+func (router *Router[T]) FindPrefix(path string) (T, contracts.RouteParams, string, error) {
+	path = router.trimPath(path)
+	for prefix := path; prefix != ""; {
+		result, params, err := router.Find(prefix)
+		if err == nil {
+			remainder := path[len(prefix):]
+			if !strings.HasPrefix(remainder, "/") {
+				remainder = "/" + remainder
+			}
+			return result, params, remainder, nil
+		}
+		if prefix == "/" {
+			break
+		}
+		if index := strings.LastIndex(prefix, "/"); index > 0 {
+			prefix = prefix[:index]
+		} else {
+			prefix = "/"
+		}
+	}
+
+	var result T
+	return result, nil, "", NotFoundErr
+}
+
+// static 查找静态路由，不区分大小写时用小写的路径查找
+func (router *Router[T]) static(path string) (T, bool) {
+	if router.caseInsensitive {
+		path = lowerASCII(path)
+	}
+	result, ok := router.paths[path]
+	return result, ok
+}
+
+// matcher 在参数路由树上做深度优先匹配，每命中一个节点调用一次 hit，hit 返回 true 时停止遍历
+type matcher[T any] struct {
+	fold   bool // 比较静态前缀时忽略 ASCII 字母的大小写，路由树中的前缀已经是小写
+	params contracts.RouteParams
+	steps  *[]TraceStep // 不为 nil 时记录匹配过程
+	hit    func(data T, params contracts.RouteParams) bool
+}
+
+func (m *matcher[T]) record(step TraceStep) {
+	if m.steps != nil {
+		*m.steps = append(*m.steps, step)
+	}
+}
+
+// hasPrefix 与 strings.HasPrefix 相同，fold 为 true 时 value 中的 ASCII 字母按小写比较
+func (m *matcher[T]) hasPrefix(value, prefix string) bool {
+	return len(value) >= len(prefix) && m.equal(value[:len(prefix)], prefix)
 }
 
-func (router *Router[T]) find(path string, tree map[string][]*RouterNode[T], params contracts.RouteParams) (T, error) {
-	for prefix, nodes := range tree {
-		index := strings.Index(path, prefix)
-		if index != 0 {
-			if prefix[len(prefix)-1:] != "/" || strings.Index(path+"/", prefix) != 0 {
+// equal 与 value == static 相同，fold 为 true 时 value 中的 ASCII 字母按小写比较
+func (m *matcher[T]) equal(value, static string) bool {
+	if !m.fold {
+		return value == static
+	}
+	if len(value) != len(static) {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != static[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// index 与 strings.Index 相同，fold 为 true 时 value 中的 ASCII 字母按小写比较
+func (m *matcher[T]) index(value, static string) int {
+	if !m.fold {
+		return strings.Index(value, static)
+	}
+	for i := 0; i+len(static) <= len(value); i++ {
+		if m.equal(value[i:i+len(static)], static) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *matcher[T]) walk(path string, tree map[string][]*RouterNode[T], prefixes []string, depth int) bool {
+	for _, prefix := range prefixes {
+		current := path
+		if !m.hasPrefix(current, prefix) {
+			if !strings.HasSuffix(prefix, "/") || !m.hasPrefix(current+"/", prefix) {
+				m.record(TraceStep{Depth: depth, Prefix: prefix, Value: path, Reason: "prefix mismatch"})
 				continue
-			} else {
-				path += "/"
+			}
+			current += "/"
+		}
+		value := current[len(prefix):]
+		m.record(TraceStep{Depth: depth, Prefix: prefix, Value: path, Accepted: true, Reason: "prefix matched"})
+		for _, node := range tree[prefix] {
+			if m.walkNode(node, prefix, value, depth) {
+				return true
 			}
 		}
-		value := path[len(prefix):]
-		for _, node := range nodes {
-			if len(node.nodes) == 0 {
-				if !strings.Contains(value, "/") && (node.reg.MatchString(value) || (node.optional && value == "")) {
-					params[node.name] = value
-					return node.data, nil
+	}
+	return false
+}
+
+func (m *matcher[T]) walkNode(node *RouterNode[T], prefix, value string, depth int) bool {
+	step := TraceStep{Depth: depth, Prefix: prefix, Param: node.name, Rule: node.rule, Optional: node.optional}
+
+	if node.terminal {
+		terminalStep := step
+		terminalStep.Value = value
+		switch {
+		case strings.Contains(value, "/"):
+			terminalStep.Reason = "value contains \"/\""
+		case node.reg.MatchString(value) || (node.optional && value == ""):
+			terminalStep.Accepted, terminalStep.Reason = true, "matched"
+			m.record(terminalStep)
+			if m.accept(node, value, node.data) {
+				return true
+			}
+		default:
+			terminalStep.Reason = "constraint not satisfied"
+		}
+		if !terminalStep.Accepted {
+			m.record(terminalStep)
+		}
+	}
+
+	for _, subPrefix := range node.prefixes {
+		if subPrefix == "/" {
+			values := strings.SplitN(value, "/", 2)
+			step.Value = values[0]
+			if node.reg.MatchString(values[0]) {
+				rest := "/"
+				if len(values) > 1 {
+					rest += values[1]
 				}
-			} else {
-				for subPrefix, subNodes := range node.nodes {
-					if subPrefix == "/" {
-						values := strings.Split(value, "/")
-						if node.reg.MatchString(values[0]) {
-							params[node.name] = values[0]
-							value = "/" + strings.Join(values[1:], "/")
-							result, err := router.find(value, node.nodes, params)
-							if err == nil {
-								return result, nil
-							}
-						}
-					}
-					index = strings.Index(value, subPrefix)
-					if index > -1 {
-						subValue := value[:index]
-						if !strings.Contains(subValue, "/") && (node.reg.MatchString(subValue) || node.optional) {
-							params[node.name] = subValue
-
-							value = value[index:]
-							if len(subNodes) == 0 && value == subPrefix {
-								return node.data, nil
-							}
-
-							result, err := router.find(value, node.nodes, params)
-							if err == nil {
-								return result, nil
-							}
-						}
-					} else if "/"+value == subPrefix && node.optional && len(subNodes) == 0 {
-						params[node.name] = ""
-						return node.data, nil
-					}
+				if m.descend(node, step, values[0], rest, subPrefix, depth) {
+					return true
 				}
+				continue
 			}
 		}
+
+		index := m.index(value, subPrefix)
+		if index > -1 {
+			subValue := value[:index]
+			step.Value = subValue
+			if strings.Contains(subValue, "/") {
+				step.Reason = fmt.Sprintf("value before %q contains \"/\"", subPrefix)
+			} else if node.reg.MatchString(subValue) || node.optional {
+				if m.descend(node, step, subValue, value[index:], subPrefix, depth) {
+					return true
+				}
+				continue
+			} else {
+				step.Reason = "constraint not satisfied"
+			}
+		} else if data, isEnd := node.suffixes[subPrefix]; isEnd && m.equal("/"+value, subPrefix) && node.optional {
+			step.Value = ""
+			step.Accepted, step.Reason = true, "matched (optional omitted)"
+			m.record(step)
+			if m.accept(node, "", data) {
+				return true
+			}
+			continue
+		} else {
+			step.Value = value
+			step.Reason = fmt.Sprintf("sub prefix %q not found", subPrefix)
+		}
+		m.record(step)
 	}
-	var result T
-	return result, NotFoundErr
+	return false
 }
 
+// descend 参数值已通过约束，继续匹配剩余路径
+func (m *matcher[T]) descend(node *RouterNode[T], step TraceStep, paramValue, rest, subPrefix string, depth int) bool {
+	step.Accepted, step.Reason = true, "constraint satisfied"
+	if data, isEnd := node.suffixes[subPrefix]; isEnd && m.equal(rest, subPrefix) {
+		step.Reason = "matched"
+		m.record(step)
+		if m.accept(node, paramValue, data) {
+			return true
+		}
+		if len(node.nodes[subPrefix]) == 0 {
+			return false
+		}
+	} else {
+		m.record(step)
+	}
+
+	m.params[node.name] = paramValue
+	if m.walk(rest, node.nodes, node.prefixes, depth+1) {
+		return true
+	}
+	delete(m.params, node.name)
+	return false
+}
+
+// accept 记录参数并通知命中，未停止遍历时撤销参数以便回溯
+func (m *matcher[T]) accept(node *RouterNode[T], value string, data T) bool {
+	m.params[node.name] = value
+	if m.hit(data, m.params) {
+		return true
+	}
+	delete(m.params, node.name)
+	return false
+}
+
+// sortedPrefixes 按优先级返回前缀：更长（更具体）的前缀优先，长度相同时按字典序
+func sortedPrefixes[T any](tree map[string][]*RouterNode[T]) []string {
+	prefixes := make([]string, 0, len(tree))
+	for prefix := range tree {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixBefore(prefixes[i], prefixes[j])
+	})
+	return prefixes
+}
+
+func prefixBefore(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a < b
+}
+
+// insertPrefix 把新前缀插入到已排序的前缀列表中
+func insertPrefix(prefixes []string, prefix string) []string {
+	index := sort.Search(len(prefixes), func(i int) bool {
+		return !prefixBefore(prefixes[i], prefix)
+	})
+	prefixes = append(prefixes, "")
+	copy(prefixes[index+1:], prefixes[index:])
+	prefixes[index] = prefix
+	return prefixes
+}
+
+// Add 添加路由，返回路由签名。参数相同的路由共用同一个参数节点，例如 /photos/{photo} 和 /photos/{photo}/edit：
+// 以参数结尾的路由数据保存在节点上（terminal），以参数加静态后缀结尾的路由数据按后缀保存（suffixes），
+// 因此共用节点的路由互不覆盖，匹配结果与添加顺序无关
+// 不区分大小写时路由的静态部分转换为小写后添加
 func (router *Router[T]) Add(route string, data T) (string, error) {
+	if router.caseInsensitive {
+		route = lowerStaticSegments(route)
+	}
 	results, signature := parseRoute(route)
 	if _, exists := router.signatures[signature]; exists {
 		return signature, RouteHasExists
@@ -115,35 +334,47 @@ func (router *Router[T]) Add(route string, data T) (string, error) {
 		router.paths[results[0]] = data
 	} else {
 		tmpTree := router.paramsRoutes
+		tmpPrefixes := &router.prefixes
 		var prefix string
-		for _, param := range results {
+		var lastNode *RouterNode[T]
+		for i, param := range results {
+			isLast := i == len(results)-1
 			if !(strings.HasPrefix(param, "{") && strings.HasSuffix(param, "}")) {
 				prefix = param
-				if tmpTree[prefix] == nil {
+				if _, exists := tmpTree[prefix]; !exists {
 					tmpTree[prefix] = make([]*RouterNode[T], 0)
+					*tmpPrefixes = insertPrefix(*tmpPrefixes, prefix)
+				}
+				if isLast && lastNode != nil {
+					// 以静态后缀结尾的路由，数据挂在最后一个参数节点的后缀上
+					lastNode.suffixes[prefix] = data
 				}
 				continue
 			}
 
 			node := NewRouteNode(param, data)
-			nodes := tmpTree[prefix]
+			nodes, hasPrefix := tmpTree[prefix]
+			if !hasPrefix {
+				*tmpPrefixes = insertPrefix(*tmpPrefixes, prefix)
+			}
 
-			if len(nodes) == 0 {
-				tmpTree[prefix] = append(nodes, node)
-				tmpTree = node.nodes
-			} else {
-				exists := false
-				for _, item := range tmpTree[prefix] {
-					if item.IsSame(node) {
-						tmpTree = item.nodes
-						exists = true
-					}
-				}
-				if !exists {
-					tmpTree[prefix] = append(nodes, node)
-					tmpTree = node.nodes
+			exists := false
+			for _, item := range nodes {
+				if item.IsSame(node) {
+					node = item
+					exists = true
 				}
 			}
+			if !exists {
+				tmpTree[prefix] = append(nodes, node)
+			}
+			if isLast {
+				node.terminal = true
+				node.data = data
+			}
+			tmpTree = node.nodes
+			tmpPrefixes = &node.prefixes
+			lastNode = node
 		}
 	}
 
@@ -0,0 +1,39 @@
+// Package routingtest 提供测试路由时使用的断言辅助函数和路由器一致性测试
+package routingtest
+
+import (
+	"github.com/goal-web/routing"
+	"strings"
+)
+
+// TestingT 断言辅助函数需要的测试接口，*testing.T 满足该接口
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// AssertMiddlewareOrder 断言匹配 method 和 path 的路由最终生效的中间件中，expected 列出的别名都存在且按给定顺序排列，
+// 其他中间件不影响断言结果
+func AssertMiddlewareOrder(t TestingT, router *routing.HttpRouter, method, path string, expected ...string) bool {
+	if helper, ok := t.(interface{ Helper() }); ok {
+		helper.Helper()
+	}
+
+	names, err := router.MiddlewareNames(method, path)
+	if err != nil {
+		t.Errorf("route [%s] %s: %s", method, path, err.Error())
+		return false
+	}
+
+	var index int
+	for _, name := range names {
+		if index < len(expected) && name == expected[index] {
+			index++
+		}
+	}
+	if index < len(expected) {
+		t.Errorf("route [%s] %s: expected middleware order [%s], got [%s]",
+			method, path, strings.Join(expected, ", "), strings.Join(names, ", "))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,216 @@
+package routingtest
+
+import (
+	"github.com/goal-web/contracts"
+)
+
+// RouterCase 路由器一致性测试用例
+type RouterCase struct {
+	Route     string
+	ErrRoutes []string                         // 与 Route 签名相同，添加时必须返回错误
+	Found     map[string]contracts.RouteParams // 必须能找到的路径以及期望的参数
+	NotFound  []string
+}
+
+// RouterCases 返回 contracts.Router 实现需要满足的用例，所有用例添加到同一个路由器中，每次调用返回新的切片
+func RouterCases() []RouterCase {
+	return []RouterCase{
+		{
+			Route: "/books/{name}_description",
+			Found: map[string]contracts.RouteParams{
+				"/books/docker_description": {"name": "docker"},
+				"/books/k8s_description":    {"name": "k8s"},
+			},
+			NotFound: []string{
+				"/books/docker/description",
+				"/books/docker/_description",
+			},
+		},
+		{
+			Route: "/books1/{name?}_description",
+			Found: map[string]contracts.RouteParams{
+				"/books1/docker_description": {"name": "docker"},
+				"/books1/k8s_description":    {"name": "k8s"},
+				"/books1/_description":       {"name": ""},
+			},
+			NotFound: []string{
+				"/books1/docker/description",
+				"/books1/docker/_description",
+			},
+		},
+		{
+			Route: "/articles/first_{type}",
+			Found: map[string]contracts.RouteParams{
+				"/articles/first_docker": {"type": "docker"},
+				"/articles/first_k8s":    {"type": "k8s"},
+			},
+			NotFound: []string{
+				"/articles/first_/description",
+				"/articles/first/_description",
+			},
+		},
+		{
+			Route: "/articles1/first_{type?}",
+			Found: map[string]contracts.RouteParams{
+				"/articles1/first_docker": {"type": "docker"},
+				"/articles1/first_k8s":    {"type": "k8s"},
+				"/articles1/first_":       {"type": ""},
+			},
+			NotFound: []string{
+				"/articles1/first_/description",
+				"/articles1/first/_description",
+			},
+		},
+		{
+			Route:     "/users1/{name}/{level?}",
+			ErrRoutes: []string{"/users1/{xx}/{xxx:.*}"},
+			Found: map[string]contracts.RouteParams{
+				"/users1/xxx":       {"name": "xxx", "level": ""},
+				"/users1/xx/sadad":  {"name": "xx", "level": "sadad"},
+				"/users1/xx/sadad/": {"name": "xx", "level": "sadad"},
+				"/users1/dd/":       {"name": "dd", "level": ""},
+			},
+			NotFound: []string{
+				"/users1/xxx/da/1",
+			},
+		},
+		{
+			Route: "/users",
+			Found: map[string]contracts.RouteParams{
+				"/users":  {},
+				"/users/": {},
+			},
+			NotFound: []string{
+				"/usersx",
+			},
+		},
+		{
+			Route: "/users/{name}",
+			Found: map[string]contracts.RouteParams{
+				"/users/xxx": {"name": "xxx"},
+			},
+			NotFound: []string{
+				"/users/xxx/da",
+			},
+		},
+		{
+			Route:     "/homepage/{name?}/hosts",
+			ErrRoutes: []string{"/homepage/{xx}/hosts"},
+			Found: map[string]contracts.RouteParams{
+				"/homepage/xxx/hosts": {"name": "xxx"},
+				"/homepage/hosts":     {"name": ""},
+			},
+			NotFound: []string{
+				"/homepage/xxx/hosts1",
+				"/homepage/hosts1",
+			},
+		},
+		{
+			Route: "/homepage/{name?}/news",
+			Found: map[string]contracts.RouteParams{
+				"/homepage/xxx/news": {"name": "xxx"},
+				"/homepage/news":     {"name": ""},
+			},
+			NotFound: []string{
+				"/homepage/xxx/news1",
+				"/homepage/news1",
+			},
+		},
+		{
+			Route: "/category/{category:[0-9]+}/archive/{archive}",
+			Found: map[string]contracts.RouteParams{
+				"/category/1/archive/any": {"category": "1", "archive": "any"},
+			},
+			NotFound: []string{
+				"/category/any/archive/any",
+			},
+		},
+		{
+			Route: "/category/{category}/posts/{archive:[0-9]+}",
+			Found: map[string]contracts.RouteParams{
+				"/category/any/posts/1": {"category": "any", "archive": "1"},
+			},
+			NotFound: []string{
+				"/category/any/posts/any",
+			},
+		},
+		{
+			Route:     "/posts/{id}",
+			ErrRoutes: []string{"/posts/{name}"},
+			Found: map[string]contracts.RouteParams{
+				"/posts/first": {"id": "first"},
+			},
+			NotFound: []string{
+				"/posts/first/xxx",
+			},
+		},
+		{
+			Route: "/archives/{id:[0-9]+?}",
+			Found: map[string]contracts.RouteParams{
+				"/archives/1": {"id": "1"},
+				"/archives/":  {"id": ""},
+				"/archives":   {"id": ""},
+			},
+			NotFound: []string{
+				"/archives/any",
+			},
+		},
+	}
+}
+
+// RunRouterConformance 用 RouterCases 检查 factory 创建的路由器，返回是否全部通过。每个用例以 Route 作为路由数据添加，
+// 查找时必须返回该用例的 Route。自定义的 contracts.Router 实现可以在测试中调用，例如 RunRouterConformance(t, routing.NewRouter[any])
+func RunRouterConformance(t TestingT, factory func() contracts.Router[any]) bool {
+	if helper, ok := t.(interface{ Helper() }); ok {
+		helper.Helper()
+	}
+
+	passed := true
+	fail := func(format string, args ...any) {
+		passed = false
+		t.Errorf(format, args...)
+	}
+
+	cases := RouterCases()
+	router := factory()
+	if !router.IsEmpty() {
+		fail("new router should be empty")
+	}
+	for _, item := range cases {
+		if signature, err := router.Add(item.Route, item.Route); err != nil {
+			fail("add %s (%s): %v", item.Route, signature, err)
+		}
+		for _, errRoute := range item.ErrRoutes {
+			if _, err := router.Add(errRoute, errRoute); err == nil {
+				fail("add %s: expected an error because it conflicts with %s", errRoute, item.Route)
+			}
+		}
+	}
+	if router.IsEmpty() {
+		fail("router should not be empty after adding routes")
+	}
+
+	for _, item := range cases {
+		for path, expected := range item.Found {
+			data, params, err := router.Find(path)
+			if err != nil {
+				fail("find %s (%s): %v", path, item.Route, err)
+				continue
+			}
+			if data != item.Route {
+				fail("find %s (%s): matched %v", path, item.Route, data)
+			}
+			for key, value := range expected {
+				if params[key] != value {
+					fail("find %s (%s): param %s = %q, expected %q", path, item.Route, key, params[key], value)
+				}
+			}
+		}
+		for _, path := range item.NotFound {
+			if data, _, err := router.Find(path); err == nil {
+				fail("find %s (%s): expected not found, matched %v", path, item.Route, data)
+			}
+		}
+	}
+	return passed
+}
@@ -0,0 +1,272 @@
+package routing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticOption 静态文件路由选项
+type StaticOption func(server *staticServer)
+
+// StaticIndex 设置目录的索引文件，默认为 index.html
+func StaticIndex(files ...string) StaticOption {
+	return func(server *staticServer) {
+		server.index = files
+	}
+}
+
+// StaticBrowse 开启目录列表，默认关闭
+func StaticBrowse() StaticOption {
+	return func(server *staticServer) {
+		server.browse = true
+	}
+}
+
+// StaticSPA 文件不存在时返回根目录的 index.html，用于单页应用的前端路由
+func StaticSPA() StaticOption {
+	return func(server *staticServer) {
+		server.spa = true
+	}
+}
+
+// StaticPrecompressed 是否使用预压缩的 .br 和 .gz 文件，默认开启
+func StaticPrecompressed(enabled bool) StaticOption {
+	return func(server *staticServer) {
+		server.precompressed = enabled
+	}
+}
+
+// StaticModTime 文件没有修改时间（例如 embed.FS）时用作 Last-Modified 的时间，通常设置为构建时间，默认不输出 Last-Modified
+func StaticModTime(modTime time.Time) StaticOption {
+	return func(server *staticServer) {
+		server.modTime = modTime
+	}
+}
+
+type staticServer struct {
+	fsys          fs.FS
+	index         []string
+	browse        bool
+	spa           bool
+	precompressed bool
+	modTime       time.Time
+
+	// 按文件内容计算的 ETag，键为文件名
+	etags sync.Map
+}
+
+// staticETag 缓存的 ETag，文件的修改时间和大小都没有变化时复用
+type staticETag struct {
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+func newStaticServer(fsys fs.FS, options []StaticOption) *staticServer {
+	server := &staticServer{fsys: fsys, index: []string{"index.html"}, precompressed: true}
+	for _, option := range options {
+		option(server)
+	}
+	return server
+}
+
+// handler 静态文件路由的处理器，返回的 http.Handler 负责输出文件
+func (server *staticServer) handler() func(params contracts.RouteParams) any {
+	return func(params contracts.RouteParams) any {
+		return &staticFile{server: server, name: params[RemainderParam]}
+	}
+}
+
+// Static 注册静态文件路由，prefix 下的 GET 和 HEAD 请求从 fsys（可以是 embed.FS）读取文件
+func (httpRouter *HttpRouter) Static(prefix string, fsys fs.FS, options ...StaticOption) contracts.Route {
+	return httpRouter.registerPrefixRoute([]string{http.MethodGet, http.MethodHead}, prefix, newStaticServer(fsys, options).handler())
+}
+
+// Static 在组内注册静态文件路由，参考 HttpRouter.Static
+func (group *Group) Static(prefix string, fsys fs.FS, options ...StaticOption) contracts.RouteGroup {
+	group.addPrefixRoute([]string{http.MethodGet, http.MethodHead}, prefix, newStaticServer(fsys, options).handler())
+	return group
+}
+
+// staticFile 一次静态文件请求
+type staticFile struct {
+	server *staticServer
+	name   string
+}
+
+func (file *staticFile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server := file.server
+	name := strings.TrimPrefix(path.Clean("/"+file.name), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(server.fsys, name)
+	if err == nil && info.IsDir() {
+		if index := server.findIndex(name); index != "" {
+			name, info = index, nil
+		} else if server.browse {
+			server.list(w, r, name)
+			return
+		} else {
+			err = fs.ErrNotExist
+		}
+	}
+
+	if err != nil {
+		if !server.spa || !errors.Is(err, fs.ErrNotExist) {
+			server.error(w, err)
+			return
+		}
+		if name = server.findIndex("."); name == "" {
+			server.error(w, err)
+			return
+		}
+	}
+
+	server.serve(w, r, name)
+}
+
+func (server *staticServer) findIndex(dir string) string {
+	for _, index := range server.index {
+		name := path.Join(dir, index)
+		if info, err := fs.Stat(server.fsys, name); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return ""
+}
+
+// serve 输出文件，支持预压缩文件、ETag 和 Last-Modified
+func (server *staticServer) serve(w http.ResponseWriter, r *http.Request, name string) {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	filename := name
+	if server.precompressed {
+		w.Header().Add("Vary", "Accept-Encoding")
+		accepted := r.Header.Get("Accept-Encoding")
+		for _, encoding := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if !acceptsEncoding(accepted, encoding.name) {
+				continue
+			}
+			if info, err := fs.Stat(server.fsys, name+encoding.ext); err == nil && !info.IsDir() {
+				filename = name + encoding.ext
+				w.Header().Set("Content-Encoding", encoding.name)
+				break
+			}
+		}
+	}
+
+	file, err := server.fsys.Open(filename)
+	if err != nil {
+		server.error(w, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		server.error(w, err)
+		return
+	}
+
+	content, isSeeker := file.(io.ReadSeeker)
+	if !isSeeker {
+		data, readErr := io.ReadAll(file)
+		if readErr != nil {
+			server.error(w, readErr)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	etag, err := server.etag(filename, info, content)
+	if err != nil {
+		server.error(w, err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	modTime := info.ModTime()
+	if modTime.IsZero() {
+		modTime = server.modTime
+	}
+	http.ServeContent(w, r, name, modTime, content)
+}
+
+// etag 按文件内容计算 ETag 并缓存，embed.FS 这类没有修改时间的文件也能区分大小相同、内容不同的文件
+func (server *staticServer) etag(filename string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if cached, exists := server.etags.Load(filename); exists {
+		if entry := cached.(staticETag); entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+			return entry.etag, nil
+		}
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16])
+	server.etags.Store(filename, staticETag{modTime: info.ModTime(), size: info.Size(), etag: etag})
+	return etag, nil
+}
+
+// list 输出目录列表
+func (server *staticServer) list(w http.ResponseWriter, r *http.Request, dir string) {
+	entries, err := fs.ReadDir(server.fsys, dir)
+	if err != nil {
+		server.error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprintln(w, "<pre>")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		link := url.URL{Path: path.Join(r.URL.Path, name)}
+		_, _ = fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(name))
+	}
+	_, _ = fmt.Fprintln(w, "</pre>")
+}
+
+func (server *staticServer) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func acceptsEncoding(header, encoding string) bool {
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		if strings.TrimSpace(name) == encoding && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
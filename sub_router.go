@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"fmt"
+	"github.com/goal-web/contracts"
+	"net/url"
+	"strings"
+)
+
+type mountedRouter struct {
+	prefix      string
+	namespace   string
+	router      contracts.HttpRouter
+	middlewares []contracts.MagicalFunc
+}
+
+// MountRouter 把一个独立构建的路由器挂载到 prefix 下，middlewares 会加在子路由器所有路由的中间件之前。
+// 子路由器是 *HttpRouter 时，它的路由在 Mount 时合并进来，路由名加上由前缀生成的命名空间（/billing 下的 invoices.show 变为 billing.invoices.show），
+// 域名和中间件保持不变，冲突会标明来源；其他实现则在 Route 时按前缀委托给子路由器查找
+func (httpRouter *HttpRouter) MountRouter(prefix string, sub contracts.HttpRouter, middlewares ...any) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	httpRouter.mountedRouters = append(httpRouter.mountedRouters, &mountedRouter{
+		prefix:      prefix,
+		namespace:   namespaceOf(prefix),
+		router:      sub,
+		middlewares: ConvertToMiddlewares(middlewares...),
+	})
+}
+
+func (mounted *mountedRouter) origin() string {
+	return fmt.Sprintf("router mounted at %s", mounted.prefix)
+}
+
+// entries 返回合并后的子路由器路由，委托查找的子路由器没有可合并的路由
+func (mounted *mountedRouter) entries() []routeEntry {
+	sub, isHttpRouter := mounted.router.(*HttpRouter)
+	if !isHttpRouter {
+		return nil
+	}
+
+	// 子路由器注册的别名优先在子路由器内解析，剩下的引用由父路由器解析
+	subMiddlewares, _ := sub.resolveMiddlewares(sub.middlewares)
+	middlewares := mergeMiddlewares(mounted.middlewares, subMiddlewares)
+	var entries []routeEntry
+	for _, entry := range sub.entries() {
+		origin := mounted.origin()
+		if entry.origin != "" {
+			origin += ", " + entry.origin
+		}
+		inherited, own := middlewares, entry.route.Middlewares()
+		if original, isRoute := entry.route.(*Route); isRoute {
+			var routeInherited []contracts.MagicalFunc
+			routeInherited, own = original.splitMiddlewares()
+			routeInherited, _ = sub.resolveMiddlewares(routeInherited)
+			inherited = mergeMiddlewares(middlewares, routeInherited)
+		}
+		own, _ = sub.resolveMiddlewares(own)
+		entries = append(entries, routeEntry{
+			route:    mountRoute(entry.route, mounted.prefix, mounted.namespace, inherited, own),
+			prefix:   entry.prefix,
+			fallback: entry.fallback,
+			origin:   origin,
+		})
+	}
+	return entries
+}
+
+// route 去掉前缀后交给子路由器查找
+func (mounted *mountedRouter) route(method string, u *url.URL, path string, caseInsensitive bool) (contracts.Route, contracts.RouteParams, error) {
+	if mounted.prefix != "/" && !hasPathPrefix(path, mounted.prefix, caseInsensitive) {
+		return nil, nil, NotFoundErr
+	}
+
+	subUrl := *u
+	subUrl.Path = "/" + strings.TrimPrefix(path[len(mounted.prefix):], "/")
+	subUrl.RawPath = ""
+
+	route, params, err := mounted.router.Route(method, &subUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &delegatedRoute{
+		Route:       route,
+		path:        joinPath(mounted.prefix, route.GetPath()),
+		name:        namespaced(mounted.namespace, route.GetName()),
+		middlewares: mergeMiddlewares(mounted.middlewares, mounted.router.Middlewares(), route.Middlewares()),
+	}, params, nil
+}
+
+// hasPathPrefix path 等于 prefix 或者以 prefix/ 开头，caseInsensitive 为 true 时忽略 ASCII 字母的大小写
+func hasPathPrefix(path, prefix string, caseInsensitive bool) bool {
+	if len(path) < len(prefix) || (len(path) > len(prefix) && path[len(prefix)] != '/') {
+		return false
+	}
+	if caseInsensitive {
+		return lowerASCII(path[:len(prefix)]) == lowerASCII(prefix)
+	}
+	return path[:len(prefix)] == prefix
+}
+
+// delegatedRoute 由委托的子路由器解析出来的路由，路径和名称带上挂载前缀，中间件包含挂载时声明的中间件
+type delegatedRoute struct {
+	contracts.Route
+	path        string
+	name        string
+	middlewares []contracts.MagicalFunc
+}
+
+func (route *delegatedRoute) GetPath() string {
+	return route.path
+}
+
+func (route *delegatedRoute) GetName() string {
+	return route.name
+}
+
+func (route *delegatedRoute) Middlewares() []contracts.MagicalFunc {
+	return route.middlewares
+}
+
+func (route *delegatedRoute) Meta() map[string]any {
+	return RouteMeta(route.Route)
+}
+
+// unwrapRoute 返回委托路由对应的原始路由
+func unwrapRoute(route contracts.Route) contracts.Route {
+	if delegated, isDelegated := route.(*delegatedRoute); isDelegated {
+		return delegated.Route
+	}
+	return route
+}
+
+// mountRoute 复制一个带有挂载前缀、命名空间的路由，复制后路由的中间件为继承的 inherited 加上路由自身的 own
+func mountRoute(route contracts.Route, prefix, namespace string, inherited, own []contracts.MagicalFunc) contracts.Route {
+	stack := mergeMiddlewares(inherited, own)
+	if original, isRoute := route.(*Route); isRoute {
+		mounted := *original
+		mounted.path = joinPath(prefix, original.path)
+		mounted.name = namespaced(namespace, original.name)
+		mounted.middlewares = stack
+		mounted.inherited = len(inherited)
+		mounted.stack = nil
+		mounted.meta = make(map[string]any, len(original.meta))
+		for key, value := range original.meta {
+			mounted.meta[key] = value
+		}
+		return &mounted
+	}
+
+	mounted := NewRoute(route.Method(), joinPath(prefix, route.GetPath()), stack, route.Handler())
+	if name := route.GetName(); name != "" {
+		mounted.Name(namespaced(namespace, name))
+	}
+	if host := route.GetHost(); host != "" {
+		mounted.Host(host)
+	}
+	return mounted
+}
+
+func joinPath(prefix, path string) string {
+	if path == "/" || path == "" {
+		return prefix
+	}
+	if prefix == "/" {
+		return path
+	}
+	return prefix + path
+}
+
+// namespaceOf 由前缀生成路由名的命名空间，忽略参数段，例如 /api/{version}/billing 生成 api.billing
+func namespaceOf(prefix string) string {
+	var segments []string
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment != "" && !strings.Contains(segment, "{") {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+func namespaced(namespace, name string) string {
+	if name == "" || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goal-web/contracts"
+	"net/url"
+	"strings"
+)
+
+var (
+	RouteNameNotFoundErr = errors.New("route name not found")
+)
+
+// buildPath 用 params 替换路径模板中的参数，参数值会做路径转义，可选参数缺失时替换为空，必选参数缺失时返回错误。
+// 模板以 / 结尾时（WithStrictSlash 注册的路由）保留末尾的 /
+func buildPath(template string, params map[string]any) (string, error) {
+	var missing []string
+	path := paramReg.ReplaceAllStringFunc(template, func(param string) string {
+		name, _, isOptional := parseRule(param)
+		if value, exists := params[name]; exists && value != nil {
+			return url.PathEscape(fmt.Sprint(value))
+		}
+		if !isOptional {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing route params [%s] for %s", strings.Join(missing, "|"), template)
+	}
+
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	if strings.HasSuffix(path, "/") && path != "/" && !hasTrailingSlash(template) {
+		path = path[:len(path)-1]
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path, nil
+}
+
+// routeParamsToMap 把路由参数转换为 buildPath 需要的参数
+func routeParamsToMap(params contracts.RouteParams) map[string]any {
+	results := make(map[string]any, len(params))
+	for key, value := range params {
+		results[key] = value
+	}
+	return results
+}
+
+// NamedRoute 根据路由名查找路由，Mount 之后使用 Mount 时建立的索引
+func (httpRouter *HttpRouter) NamedRoute(name string) (contracts.Route, error) {
+	if httpRouter.names != nil {
+		if route, exists := httpRouter.names[name]; exists {
+			return route, nil
+		}
+		return nil, fmt.Errorf("%w: %s", RouteNameNotFoundErr, name)
+	}
+
+	for _, entry := range httpRouter.entries() {
+		if entry.route.GetName() == name {
+			return entry.route, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", RouteNameNotFoundErr, name)
+}
+
+// URL 根据路由名和参数生成路径，例如 URL("users.show", map[string]any{"id": 1}) 生成 /users/1
+func (httpRouter *HttpRouter) URL(name string, params map[string]any) (string, error) {
+	route, err := httpRouter.NamedRoute(name)
+	if err != nil {
+		return "", err
+	}
+	return buildPath(route.GetPath(), params)
+}
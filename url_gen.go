@@ -0,0 +1,205 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+)
+
+// urlHelperIdentifiers 生成的 URL 函数中已经使用的标识符，参数名与之相同时需要改名
+var urlHelperIdentifiers = map[string]bool{"url": true, "strconv": true, "strings": true, "cleanRoutePath": true}
+
+// NamedRoutes 返回路由名到路径模板的映射，不包含前缀路由
+func (httpRouter *HttpRouter) NamedRoutes() map[string]string {
+	routes := map[string]string{}
+	for _, entry := range httpRouter.entries() {
+		if name := entry.route.GetName(); name != "" && !entry.prefix {
+			routes[name] = entry.route.GetPath()
+		}
+	}
+	return routes
+}
+
+// NamedRoutesFromCache 从 HttpRouter.Cache 生成的缓存中读取路由名到路径模板的映射
+func NamedRoutesFromCache(r io.Reader) (map[string]string, error) {
+	var cache routeCache
+	if err := json.NewDecoder(r).Decode(&cache); err != nil {
+		return nil, fmt.Errorf("invalid route cache: %w", err)
+	}
+
+	routes := map[string]string{}
+	for _, route := range cache.Routes {
+		if route.Name != "" && !route.Prefix {
+			routes[route.Name] = route.Path
+		}
+	}
+	return routes, nil
+}
+
+// NamedRoutesFromConfig 从路由配置中读取路由名到路径模板的映射，跳过禁用的路由
+func NamedRoutesFromConfig(configs []RouteConfig) map[string]string {
+	routes := map[string]string{}
+	for _, config := range configs {
+		if config.Name != "" && !config.Disabled {
+			routes[config.Name] = config.Path
+		}
+	}
+	return routes
+}
+
+// GenerateURLHelpers 根据路由名和路径模板生成类型安全的 URL 函数，例如 users.show 生成 UsersShow(id uint) string。
+// 约束为整数的参数生成 uint 参数，其余为 string 参数并做路径转义；可选的 uint 参数为 *uint，传 nil 时省略，可选的 string 参数传空字符串时省略。
+// 不同参数名转换后的标识符相同时，后面的参数加上序号，例如 {user_id} 和 {userId} 生成 userId 和 userId2
+func GenerateURLHelpers(w io.Writer, packageName string, routes map[string]string) error {
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body strings.Builder
+	var usesUrl, usesStrconv, usesClean bool
+	functions := map[string]string{}
+	for _, name := range names {
+		template := routes[name]
+		function := exportedName(name)
+		if function == "" {
+			return fmt.Errorf("route name %q can not be converted to a function name", name)
+		}
+		if existing, exists := functions[function]; exists {
+			return fmt.Errorf("route names %q and %q both generate %s", existing, name, function)
+		}
+		functions[function] = name
+
+		var arguments, statements, parts []string
+		needsClean := strings.Contains(template, "//") || (strings.HasSuffix(template, "/") && template != "/")
+		results, _ := parseRoute(template)
+
+		// 先为所有参数分配标识符，再为可选整数参数的局部变量分配，参数和局部变量在同一个作用域
+		used := map[string]bool{}
+		identifiers := make([]string, len(results))
+		for i, result := range results {
+			if strings.HasPrefix(result, "{") && strings.HasSuffix(result, "}") {
+				param, _, _ := parseRule(result)
+				identifiers[i] = uniqueIdentifier(urlHelperIdentifier(param), used)
+			}
+		}
+
+		for i, result := range results {
+			if identifiers[i] == "" {
+				parts = append(parts, fmt.Sprintf("%q", result))
+				continue
+			}
+
+			_, rule, isOptional := parseRule(result)
+			identifier := identifiers[i]
+			isInt := isIntegerRule(rule)
+			needsClean = needsClean || isOptional
+			switch {
+			case isInt && isOptional:
+				usesStrconv = true
+				value := uniqueIdentifier(identifier+"Value", used)
+				arguments = append(arguments, identifier+" *uint")
+				statements = append(statements, fmt.Sprintf("var %[2]s string\nif %[1]s != nil {\n%[2]s = strconv.FormatUint(uint64(*%[1]s), 10)\n}\n", identifier, value))
+				parts = append(parts, value)
+			case isInt:
+				usesStrconv = true
+				arguments = append(arguments, identifier+" uint")
+				parts = append(parts, fmt.Sprintf("strconv.FormatUint(uint64(%s), 10)", identifier))
+			default:
+				usesUrl = true
+				arguments = append(arguments, identifier+" string")
+				parts = append(parts, fmt.Sprintf("url.PathEscape(%s)", identifier))
+			}
+		}
+
+		path := strings.Join(parts, " + ")
+		if path == "" {
+			path = `"/"`
+		}
+		if needsClean {
+			usesClean = true
+			path = fmt.Sprintf("cleanRoutePath(%s, %t)", path, hasTrailingSlash(template))
+		}
+		fmt.Fprintf(&body, "\n// %s %s %s\nfunc %s(%s) string {\n%sreturn %s\n}\n",
+			function, name, template, function, strings.Join(arguments, ", "), strings.Join(statements, ""), path)
+	}
+
+	var source strings.Builder
+	source.WriteString("// Code generated by routing-urls. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "package %s\n", packageName)
+	var imports []string
+	if usesUrl {
+		imports = append(imports, `"net/url"`)
+	}
+	if usesStrconv {
+		imports = append(imports, `"strconv"`)
+	}
+	if usesClean {
+		imports = append(imports, `"strings"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&source, "\nimport (\n%s\n)\n", strings.Join(imports, "\n"))
+	}
+	source.WriteString(body.String())
+	if usesClean {
+		source.WriteString(`
+// cleanRoutePath 与 HttpRouter.URL 一致，合并省略可选参数后出现的 //，路由模板不以 / 结尾时去掉末尾的 /
+func cleanRoutePath(path string, trailingSlash bool) string {
+for strings.Contains(path, "//") {
+path = strings.ReplaceAll(path, "//", "/")
+}
+if strings.HasSuffix(path, "/") && path != "/" && !trailingSlash {
+path = path[:len(path)-1]
+}
+if path == "" {
+path = "/"
+}
+return path
+}
+`)
+	}
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// urlHelperIdentifier 把路由参数名转换为合法的 Go 参数名
+func urlHelperIdentifier(param string) string {
+	identifier := exportedName(param)
+	if identifier == "" {
+		return "param"
+	}
+	identifier = strings.ToLower(identifier[:1]) + identifier[1:]
+	if token.IsKeyword(identifier) || urlHelperIdentifiers[identifier] {
+		identifier += "Param"
+	}
+	return identifier
+}
+
+// uniqueIdentifier 标识符已经使用时加上序号，并记录到 used 中
+func uniqueIdentifier(identifier string, used map[string]bool) string {
+	candidate := identifier
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", identifier, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// isIntegerRule 约束只允许数字时返回 true，例如 [0-9]+、\d+、^[0-9]+$
+func isIntegerRule(rule string) bool {
+	switch strings.TrimSuffix(strings.TrimPrefix(rule, "^"), "$") {
+	case "[0-9]+", `\d+`:
+		return true
+	}
+	return false
+}
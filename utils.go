@@ -1,9 +1,13 @@
 package routing
 
 import (
+	"fmt"
 	"github.com/goal-web/container"
 	"github.com/goal-web/contracts"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 func parseRule(param string) (string, string, bool) {
@@ -59,11 +63,105 @@ func parseRoute(route string) ([]string, string) {
 	return results, signature
 }
 
+// trimPath 去掉路径末尾的 /，根路径除外
+func trimPath(path string) string {
+	if strings.HasSuffix(path, "/") && path != "/" {
+		return path[:len(path)-1]
+	}
+	return path
+}
+
+// toHandler 把处理器转换为 contracts.MagicalFunc，已经是 contracts.MagicalFunc 的原样返回
+func toHandler(handler any) contracts.MagicalFunc {
+	if magicalFunc, isMagicalFunc := handler.(contracts.MagicalFunc); isMagicalFunc {
+		return magicalFunc
+	}
+	return newMagicalFunc(handler)
+}
+
+// funcIdentities 记录 newMagicalFunc 创建的 contracts.MagicalFunc 对应的原始函数名称，用于路由列表等场景展示处理器和中间件。
+// 名称单独记录而不是包装 contracts.MagicalFunc，保持容器返回的具体类型
+var funcIdentities sync.Map
+
+// funcPointers 记录 newMagicalFunc 创建的 contracts.MagicalFunc 对应的原始函数指针，用于按函数排除中间件
+var funcPointers sync.Map
+
+func newMagicalFunc(fn any) contracts.MagicalFunc {
+	magicalFunc := container.NewMagicalFunc(fn)
+	if reflect.TypeOf(magicalFunc).Comparable() {
+		funcIdentities.Store(magicalFunc, funcName(fn))
+		if value := reflect.ValueOf(fn); value.Kind() == reflect.Func {
+			funcPointers.Store(magicalFunc, value.Pointer())
+		}
+	}
+	return magicalFunc
+}
+
+// funcPointer 返回中间件对应的原始函数指针，别名中间件和可终止中间件按包装的中间件查找
+func funcPointer(fn contracts.MagicalFunc) (uintptr, bool) {
+	switch value := fn.(type) {
+	case *aliasMiddleware:
+		return funcPointer(value.MagicalFunc)
+	case *terminableMiddleware:
+		return funcPointer(value.MagicalFunc)
+	}
+	if reflect.TypeOf(fn).Comparable() {
+		if pointer, exists := funcPointers.Load(fn); exists {
+			return pointer.(uintptr), true
+		}
+	}
+	return 0, false
+}
+
+func funcName(fn any) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", fn)
+	}
+	if runtimeFunc := runtime.FuncForPC(value.Pointer()); runtimeFunc != nil {
+		return runtimeFunc.Name()
+	}
+	return value.Type().String()
+}
+
+// FuncIdentity 返回处理器或者中间件的标识：中间件别名、函数名或者控制器方法名，都没有时返回函数签名
+func FuncIdentity(fn contracts.MagicalFunc) string {
+	if reflect.TypeOf(fn).Comparable() {
+		if identity, exists := funcIdentities.Load(fn); exists {
+			return identity.(string)
+		}
+	}
+
+	switch value := fn.(type) {
+	case *aliasMiddleware:
+		return value.name
+	case *middlewareReference:
+		return value.name
+	case *controllerAction:
+		return value.identity
+	case *terminableMiddleware:
+		return FuncIdentity(value.MagicalFunc)
+	}
+
+	var arguments, returns []string
+	for _, argument := range fn.Arguments() {
+		arguments = append(arguments, argument.String())
+	}
+	for _, result := range fn.Returns() {
+		returns = append(returns, result.String())
+	}
+	return fmt.Sprintf("func(%s) (%s)", strings.Join(arguments, ", "), strings.Join(returns, ", "))
+}
+
 func ConvertToMiddlewares(middlewares ...any) (results []contracts.MagicalFunc) {
 	for _, middleware := range middlewares {
+		if name, isReference := middleware.(string); isReference {
+			results = append(results, newMiddlewareReference(name))
+			continue
+		}
 		magicalFunc, isMiddleware := middleware.(contracts.MagicalFunc)
 		if !isMiddleware {
-			magicalFunc = container.NewMagicalFunc(middleware)
+			magicalFunc = newMagicalFunc(middleware)
 		}
 		if magicalFunc.NumOut() != 1 {
 			panic(MiddlewareError)
@@ -72,3 +170,16 @@ func ConvertToMiddlewares(middlewares ...any) (results []contracts.MagicalFunc)
 	}
 	return
 }
+
+// mergeMiddlewares 把多组中间件合并到一个新的切片中，避免共享底层数组导致兄弟组或路由之间互相覆盖
+func mergeMiddlewares(stacks ...[]contracts.MagicalFunc) []contracts.MagicalFunc {
+	var size int
+	for _, stack := range stacks {
+		size += len(stack)
+	}
+	results := make([]contracts.MagicalFunc, 0, size)
+	for _, stack := range stacks {
+		results = append(results, stack...)
+	}
+	return results
+}